@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"github.com/siro20/lavacli/pkg/lava"
 	"gopkg.in/yaml.v2"
 )
 
@@ -45,6 +46,156 @@ func (c *listDeviceTypesCmd) Run(ctx *context) error {
 	return nil
 }
 
+type showDeviceTypesCmd struct {
+	Name string `arg:"" required:"" help:"Name of the device-type."`
+	Yaml bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *showDeviceTypesCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.DevicesTypesShow(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("name            : %s\n", ret.Name)
+		fmt.Printf("description     : %s\n", ret.Description)
+		fmt.Printf("display         : %v\n", ret.Display)
+		fmt.Printf("owners only     : %v\n", ret.OwnersOnly)
+		fmt.Printf("health disabled : %v\n", ret.HealthDisabled)
+		fmt.Printf("health frequency: %d %s\n", ret.HealthFrequency, ret.HealthDenominator)
+		fmt.Printf("aliases         : %v\n", ret.Aliases)
+		fmt.Printf("devices         : %v\n", ret.Devices)
+		fmt.Printf("default template: %v\n", ret.DefaultTemplate)
+	}
+
+	return nil
+}
+
+type addDeviceTypesCmd struct {
+	Name              string `arg:"" required:"" help:"Name of the device-type."`
+	Description       string `flag:"" optional:"" help:"Description of the device-type."`
+	Hide              bool   `flag:"" optional:"" help:"Hide the device-type in the web interface." default:"false"`
+	OwnersOnly        bool   `flag:"" optional:"" help:"Only owners can submit jobs." default:"false"`
+	HealthFrequency   int    `flag:"" optional:"" help:"Number of hours or jobs between two health checks." default:"24"`
+	HealthDenominator string `flag:"" optional:"" help:"[hours, jobs]" default:"hours" enum:"hours,jobs"`
+}
+
+func (c *addDeviceTypesCmd) Run(ctx *context) error {
+	display := !c.Hide
+
+	return ctx.LavaCon.DevicesTypesAdd(c.Name, lava.DeviceTypeSettings{
+		Description:       c.Description,
+		Display:           &display,
+		OwnersOnly:        &c.OwnersOnly,
+		HealthFrequency:   &c.HealthFrequency,
+		HealthDenominator: c.HealthDenominator,
+	})
+}
+
+type updateDeviceTypesCmd struct {
+	Name              string `arg:"" required:"" help:"Name of the device-type."`
+	Description       string `flag:"" optional:"" help:"Description of the device-type. Default:Unchanged."`
+	Display           bool   `flag:"" optional:"" help:"Show the device-type in the web interface." default:"false"`
+	Hide              bool   `flag:"" optional:"" help:"Hide the device-type in the web interface." default:"false"`
+	OwnersOnly        bool   `flag:"" optional:"" help:"Only owners can submit jobs." default:"false"`
+	AllUsers          bool   `flag:"" optional:"" help:"All users can submit jobs." default:"false"`
+	HealthFrequency   int    `flag:"" optional:"" help:"Number of hours or jobs between two health checks. Default:Unchanged."`
+	HealthDenominator string `flag:"" optional:"" help:"[hours, jobs]. Default:Unchanged."`
+	HealthDisabled    bool   `flag:"" optional:"" help:"Disable health checks." default:"false"`
+	HealthEnabled     bool   `flag:"" optional:"" help:"Enable health checks." default:"false"`
+}
+
+func (c *updateDeviceTypesCmd) Run(ctx *context) error {
+	var s lava.DeviceTypeSettings
+	var err error
+
+	s.Description = c.Description
+	s.HealthDenominator = c.HealthDenominator
+	if c.HealthFrequency > 0 {
+		s.HealthFrequency = &c.HealthFrequency
+	}
+	s.Display, err = optionalBool(c.Display, c.Hide)
+	if err != nil {
+		return fmt.Errorf("--display and --hide: %v", err)
+	}
+	s.OwnersOnly, err = optionalBool(c.OwnersOnly, c.AllUsers)
+	if err != nil {
+		return fmt.Errorf("--owners-only and --all-users: %v", err)
+	}
+	s.HealthDisabled, err = optionalBool(c.HealthDisabled, c.HealthEnabled)
+	if err != nil {
+		return fmt.Errorf("--health-disabled and --health-enabled: %v", err)
+	}
+
+	return ctx.LavaCon.DevicesTypesUpdate(c.Name, s)
+}
+
+type listAliasesCmd struct {
+	Name string `arg:"" required:"" help:"Name of the device-type."`
+	Yaml bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listAliasesCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.DevicesTypesAliasesList(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Aliases:\n")
+		for i := range ret {
+			fmt.Printf("* %s\n", ret[i])
+		}
+	}
+
+	return nil
+}
+
+type addAliasCmd struct {
+	Name  string `arg:"" required:"" help:"Name of the device-type."`
+	Alias string `arg:"" required:"" help:"The alias to add."`
+}
+
+func (c *addAliasCmd) Run(ctx *context) error {
+	return ctx.LavaCon.DevicesTypesAliasesAdd(c.Name, c.Alias)
+}
+
+type deleteAliasCmd struct {
+	Name  string `arg:"" required:"" help:"Name of the device-type."`
+	Alias string `arg:"" required:"" help:"The alias to delete."`
+}
+
+func (c *deleteAliasCmd) Run(ctx *context) error {
+	return ctx.LavaCon.DevicesTypesAliasesDelete(c.Name, c.Alias)
+}
+
 type getTemplateCmd struct {
 	Name string `arg:"" required:"" help:"Name of the template."`
 }
@@ -125,8 +276,18 @@ type healthCheckCmd struct {
 	Set setHealthCheckCmd `cmd:"" help:"Set (upload) a health-check to the server."`
 }
 
+type aliasesCmd struct {
+	List   listAliasesCmd `cmd:"" help:"Lists aliases"`
+	Add    addAliasCmd    `cmd:"" help:"Add an alias"`
+	Delete deleteAliasCmd `cmd:"" help:"Delete an alias"`
+}
+
 type deviceTypesCmd struct {
-	List        listDeviceTypesCmd `cmd:"" help:"Lists device types"`
-	Template    templateCmd        `cmd:"" help:"Handle device templates"`
-	HealthCheck healthCheckCmd     `cmd:"" help:"Handle health checks"`
+	List        listDeviceTypesCmd   `cmd:"" help:"Lists device types"`
+	Show        showDeviceTypesCmd   `cmd:"" help:"Show device type properties"`
+	Add         addDeviceTypesCmd    `cmd:"" help:"Add a device type"`
+	Update      updateDeviceTypesCmd `cmd:"" help:"Update device type properties"`
+	Aliases     aliasesCmd           `cmd:"" help:"Handle device type aliases"`
+	Template    templateCmd          `cmd:"" help:"Handle device templates"`
+	HealthCheck healthCheckCmd       `cmd:"" help:"Handle health checks"`
 }
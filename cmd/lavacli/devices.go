@@ -5,17 +5,28 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
 
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lavatools"
 	"gopkg.in/yaml.v2"
 )
 
 type listDevicesCmd struct {
-	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
-	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+	Yaml   bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON   bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+	Type   string `flag:"" optional:"" help:"Only list devices of this device-type"`
+	State  string `flag:"" optional:"" help:"[IDLE, RESERVED, RUNNING]"`
+	Health string `flag:"" optional:"" help:"[GOOD, UNKNOWN, LOOPING, BAD, MAINTENANCE, RETIRED]"`
 }
 
 func (c *listDevicesCmd) Run(ctx *context) error {
-	ret, err := ctx.LavaCon.DevicesList()
+	ret, err := ctx.LavaCon.DevicesListFiltered(lava.DevicesFilter{DeviceType: c.Type,
+		State:  c.State,
+		Health: c.Health,
+	})
 	if err != nil {
 		return err
 	}
@@ -117,9 +128,31 @@ func (c *listDevicesTagCmd) Run(ctx *context) error {
 type addDevicesTagCmd struct {
 	DeviceName string `arg:"" required:"" help:"The device name to show."`
 	Name       string `arg:"" required:"" help:"The tag to add to."`
+	Create     bool   `flag:"" optional:"" help:"Create the tag without asking if it doesn't exist" default:"false"`
 }
 
 func (c *addDevicesTagCmd) Run(ctx *context) error {
+	tags, err := ctx.LavaCon.TagsList()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range tags {
+		if tags[i].Name == c.Name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		if !c.Create && !confirm(fmt.Sprintf("Tag %s doesn't exist. Create it?", c.Name)) {
+			return fmt.Errorf("Tag %s not found on server", c.Name)
+		}
+		err = ctx.LavaCon.TagsAdd(c.Name, "")
+		if err != nil {
+			return err
+		}
+	}
+
 	return ctx.LavaCon.DevicesTagsAdd(c.DeviceName, c.Name)
 }
 
@@ -132,6 +165,181 @@ func (c *deleteDeviceTagCmd) Run(ctx *context) error {
 	return ctx.LavaCon.DevicesTagsDelete(c.DeviceName, c.Name)
 }
 
+// checkDeviceTypeAndWorker makes sure the device-type and worker are known
+// to the server. Empty names are not checked.
+func checkDeviceTypeAndWorker(ctx *context, deviceType string, worker string) error {
+	if deviceType != "" {
+		types, err := ctx.LavaCon.DevicesTypesList(true)
+		if err != nil {
+			return err
+		}
+		found := false
+		for i := range types {
+			if types[i].Name == deviceType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Device-type %s not found on server", deviceType)
+		}
+	}
+	if worker != "" {
+		workers, err := ctx.LavaCon.WorkersList()
+		if err != nil {
+			return err
+		}
+		found := false
+		for i := range workers {
+			if workers[i].Hostname == worker {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Worker %s not found on server", worker)
+		}
+	}
+	return nil
+}
+
+type addDevicesCmd struct {
+	DeviceName    string `arg:"" required:"" help:"The device name to add."`
+	DeviceType    string `arg:"" required:"" help:"The device-type of the device."`
+	Worker        string `arg:"" required:"" help:"The worker the device is attached to."`
+	Description   string `flag:"" optional:"" help:"Description of the device."`
+	Health        string `flag:"" optional:"" help:"[GOOD, UNKNOWN, LOOPING, BAD, MAINTENANCE, RETIRED]"`
+	PhysicalOwner string `flag:"" optional:"" help:"Username of the physical owner."`
+	PhysicalGroup string `flag:"" optional:"" help:"Group of the physical owner."`
+	Private       bool   `flag:"" optional:"" help:"Make the device private." default:"false"`
+}
+
+func (c *addDevicesCmd) Run(ctx *context) error {
+	err := checkDeviceTypeAndWorker(ctx, c.DeviceType, c.Worker)
+	if err != nil {
+		return err
+	}
+
+	public := !c.Private
+	return ctx.LavaCon.DevicesAdd(c.DeviceName, lava.DeviceSettings{
+		DeviceType:    c.DeviceType,
+		Worker:        c.Worker,
+		Description:   c.Description,
+		Health:        c.Health,
+		PhysicalOwner: c.PhysicalOwner,
+		PhysicalGroup: c.PhysicalGroup,
+		Public:        &public,
+	})
+}
+
+type updateDevicesCmd struct {
+	DeviceName    string `arg:"" required:"" help:"The device name to update."`
+	DeviceType    string `flag:"" optional:"" help:"The device-type of the device. Default:Unchanged."`
+	Worker        string `flag:"" optional:"" help:"The worker the device is attached to. Default:Unchanged."`
+	Description   string `flag:"" optional:"" help:"Description of the device. Default:Unchanged."`
+	Health        string `flag:"" optional:"" help:"[GOOD, UNKNOWN, LOOPING, BAD, MAINTENANCE, RETIRED]. Default:Unchanged."`
+	PhysicalOwner string `flag:"" optional:"" help:"Username of the physical owner. Default:Unchanged."`
+	PhysicalGroup string `flag:"" optional:"" help:"Group of the physical owner. Default:Unchanged."`
+	Public        bool   `flag:"" optional:"" help:"Make the device public." default:"false"`
+	Private       bool   `flag:"" optional:"" help:"Make the device private." default:"false"`
+}
+
+func (c *updateDevicesCmd) Run(ctx *context) error {
+	public, err := optionalBool(c.Public, c.Private)
+	if err != nil {
+		return fmt.Errorf("--public and --private: %v", err)
+	}
+
+	err = checkDeviceTypeAndWorker(ctx, c.DeviceType, c.Worker)
+	if err != nil {
+		return err
+	}
+
+	return ctx.LavaCon.DevicesUpdate(c.DeviceName, lava.DeviceSettings{
+		DeviceType:    c.DeviceType,
+		Worker:        c.Worker,
+		Description:   c.Description,
+		Health:        c.Health,
+		PhysicalOwner: c.PhysicalOwner,
+		PhysicalGroup: c.PhysicalGroup,
+		Public:        public,
+	})
+}
+
+type maintenanceDevicesCmd struct {
+	DeviceName string        `arg:"" required:"" help:"The device to put into maintenance."`
+	NoWait     bool          `flag:"" optional:"" help:"Don't wait for the current job to finish" default:"false"`
+	Force      bool          `flag:"" optional:"" help:"Cancel the current job" default:"false"`
+	Interval   time.Duration `flag:"" optional:"" help:"Interval between two device state checks" default:"15s"`
+	Timeout    time.Duration `flag:"" optional:"" help:"Maximum time to wait for the current job. 0 waits forever." default:"0"`
+}
+
+func (c *maintenanceDevicesCmd) Run(ctx *context) error {
+	tools, err := lavatools.NewLavaTools(ctx.LavaCon, toolsOptions)
+	if err != nil {
+		return err
+	}
+
+	return tools.DeviceMaintenance(c.DeviceName, lavatools.MaintenanceOptions{
+		Wait:         !c.NoWait,
+		Force:        c.Force,
+		PollInterval: c.Interval,
+		Timeout:      c.Timeout,
+	})
+}
+
+type restoreDevicesCmd struct {
+	DeviceName string `arg:"" required:"" help:"The device to put back into service."`
+}
+
+func (c *restoreDevicesCmd) Run(ctx *context) error {
+	tools, err := lavatools.NewLavaTools(ctx.LavaCon, toolsOptions)
+	if err != nil {
+		return err
+	}
+
+	return tools.DeviceRestore(c.DeviceName)
+}
+
+type getDevicesDictCmd struct {
+	DeviceName string `arg:"" required:"" help:"The device name."`
+	Render     bool   `flag:"" optional:"" help:"Render the dictionary on the server" default:"false"`
+}
+
+func (c *getDevicesDictCmd) Run(ctx *context) error {
+
+	ret, err := ctx.LavaCon.DevicesDictionaryGet(c.DeviceName, c.Render)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", ret)
+
+	return nil
+}
+
+type setDevicesDictCmd struct {
+	DeviceName string `arg:"" required:"" help:"The device name."`
+	Filename   string `arg:"" required:"" help:"Local filename of the device dictionary."`
+}
+
+func (c *setDevicesDictCmd) Run(ctx *context) error {
+	dictFile, err := ioutil.ReadFile(c.Filename)
+	if err != nil && !strings.HasSuffix(c.Filename, ".jinja2") {
+		dictFile, err = ioutil.ReadFile(c.Filename + ".jinja2")
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read file: #%v ", err)
+	}
+
+	return ctx.LavaCon.DevicesDictionarySet(c.DeviceName, string(dictFile))
+}
+
+type devicesDictCmd struct {
+	Get getDevicesDictCmd `cmd:"" help:"Get (download) a device dictionary from the server."`
+	Set setDevicesDictCmd `cmd:"" help:"Set (upload) a device dictionary to the server."`
+}
+
 type devicesTagsCmd struct {
 	List   listDevicesTagCmd  `cmd:"" help:"Lists tags"`
 	Add    addDevicesTagCmd   `cmd:"" help:"Add a tag"`
@@ -139,7 +347,12 @@ type devicesTagsCmd struct {
 }
 
 type devicesCmd struct {
-	List listDevicesCmd `cmd:"" help:"Lists devices"`
-	Tags devicesTagsCmd `cmd:"" help:"Handle device tags"`
-	Show showDevicesCmd `cmd:"" help:"Show device properties"`
+	List        listDevicesCmd        `cmd:"" help:"Lists devices"`
+	Tags        devicesTagsCmd        `cmd:"" help:"Handle device tags"`
+	Show        showDevicesCmd        `cmd:"" help:"Show device properties"`
+	Add         addDevicesCmd         `cmd:"" help:"Add a device"`
+	Update      updateDevicesCmd      `cmd:"" help:"Update device properties"`
+	Dict        devicesDictCmd        `cmd:"" help:"Handle device dictionaries"`
+	Maintenance maintenanceDevicesCmd `cmd:"" help:"Put a device into maintenance"`
+	Restore     restoreDevicesCmd     `cmd:"" help:"Put a device back into service, triggers a health check"`
 }
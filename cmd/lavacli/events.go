@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"gopkg.in/yaml.v2"
+)
+
+// jobMatcher matches events of a job given by its ID or multinode sub_id.
+// Events of multinode sub jobs carry the sub_id, which is resolved to the
+// job ID once per sub job.
+type jobMatcher struct {
+	con      lava.Client
+	number   string
+	id       int
+	resolved map[string]int
+}
+
+func newJobMatcher(con lava.Client, number string) (*jobMatcher, error) {
+	id, err := con.JobsResolve(number)
+	if err != nil {
+		return nil, err
+	}
+	return &jobMatcher{con: con, number: number, id: id, resolved: map[string]int{}}, nil
+}
+
+// match returns true if the event relates to the job
+func (m *jobMatcher) match(ev *lava.Event) bool {
+	n := ev.JobNumber()
+	if n == "" {
+		return false
+	}
+	if n == m.number {
+		return true
+	}
+	if !strings.Contains(n, ".") {
+		return ev.JobID() == m.id
+	}
+	id, ok := m.resolved[n]
+	if !ok {
+		// Sub jobs which can't be resolved, like private ones, don't match
+		id, _ = m.con.JobsResolve(n)
+		m.resolved[n] = id
+	}
+	return id == m.id
+}
+
+// listen connects to the event stream, using the URI if given
+func listen(ctx *context, uri string) (*lava.EventListener, error) {
+	if uri != "" {
+		return ctx.LavaCon.EventsListenURI(uri)
+	}
+	return ctx.LavaCon.EventsListen()
+}
+
+type listenEventsCmd struct {
+	Type      []string `flag:"" optional:"" help:"Only print events of these types: testjob, device, worker, event"`
+	Job       string   `flag:"" optional:"" help:"Only print events of this job, given by ID or multinode sub_id"`
+	Device    string   `flag:"" optional:"" help:"Only print events of this device"`
+	EventsURI string   `flag:"" optional:"" name:"events-uri" help:"ws:// or wss:// URI of the event stream. Default:Derived from the server URI."`
+	Yaml      bool     `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON      bool     `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+// match returns true if the event passes all filters
+func (c *listenEventsCmd) match(ev *lava.Event, job *jobMatcher) bool {
+	if len(c.Type) > 0 {
+		found := false
+		for _, t := range c.Type {
+			if strings.EqualFold(t, ev.Type) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if job != nil && !job.match(ev) {
+		return false
+	}
+	if c.Device != "" && ev.DeviceName() != c.Device {
+		return false
+	}
+	return true
+}
+
+func printEvent(ev *lava.Event) {
+	switch {
+	case ev.TestJob != nil:
+		fmt.Printf("%s [testjob] %s %s/%s on %s (%s)\n", ev.DateTime, ev.JobNumber(),
+			ev.TestJob.State, ev.TestJob.Health, ev.TestJob.Device, ev.TestJob.Description)
+	case ev.Device != nil:
+		fmt.Printf("%s [device] %s %s/%s\n", ev.DateTime, ev.Device.Device, ev.Device.State, ev.Device.Health)
+	case ev.Worker != nil:
+		fmt.Printf("%s [worker] %s %s/%s\n", ev.DateTime, ev.Worker.Worker, ev.Worker.State, ev.Worker.Health)
+	case ev.Job != nil:
+		fmt.Printf("%s [event] %s %v\n", ev.DateTime, ev.JobNumber(), ev.Job.Message)
+	default:
+		fmt.Printf("%s [%s] %s\n", ev.DateTime, ev.Type, string(ev.Data))
+	}
+}
+
+func (c *listenEventsCmd) Run(ctx *context) error {
+	var job *jobMatcher
+	var err error
+	if c.Job != "" {
+		job, err = newJobMatcher(ctx.LavaCon, c.Job)
+		if err != nil {
+			return err
+		}
+	}
+
+	l, err := listen(ctx, c.EventsURI)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	for {
+		ev, err := l.Next()
+		if err != nil {
+			return err
+		}
+		if !c.match(ev, job) {
+			continue
+		}
+
+		if c.Yaml {
+			d, err := yaml.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("---\n%s", string(d))
+		} else if c.JSON {
+			d, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(d))
+		} else {
+			printEvent(ev)
+		}
+	}
+}
+
+type waitEventsCmd struct {
+	Job       string        `flag:"" required:"" help:"Job ID or multinode sub_id"`
+	State     string        `flag:"" optional:"" help:"State to wait for, e.g. running or finished" default:"finished"`
+	Health    string        `flag:"" optional:"" help:"Health to wait for, e.g. complete. Default:Any."`
+	Timeout   time.Duration `flag:"" optional:"" help:"Maximum time to wait. 0 waits forever." default:"0"`
+	EventsURI string        `flag:"" optional:"" name:"events-uri" help:"ws:// or wss:// URI of the event stream. Default:Derived from the server URI."`
+}
+
+// reached returns true if state and health match the requested transition
+func (c *waitEventsCmd) reached(state string, health string) bool {
+	if !strings.EqualFold(state, c.State) {
+		return false
+	}
+	return c.Health == "" || strings.EqualFold(health, c.Health)
+}
+
+// finished returns an error with the exit code of the job if it finished
+// without reaching the requested transition, as it never will
+func (c *waitEventsCmd) finished(state string, health string) error {
+	if !strings.EqualFold(state, "finished") {
+		return nil
+	}
+	code := jobExitCode(&lava.JobState{State: state, Health: health})
+	if code == exitOK {
+		code = exitError
+	}
+	want := c.State
+	if c.Health != "" {
+		want += "/" + c.Health
+	}
+	return exitCodeError{code: code,
+		msg: fmt.Sprintf("Job %s finished %s/%s without reaching %s", c.Job, state, health, want)}
+}
+
+func (c *waitEventsCmd) Run(ctx *context) error {
+	l, err := listen(ctx, c.EventsURI)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	m, err := newJobMatcher(ctx.LavaCon, c.Job)
+	if err != nil {
+		return err
+	}
+
+	// The transition might have happened before the listener was connected
+	job, err := ctx.LavaCon.JobsShow(m.id)
+	if err != nil {
+		return err
+	}
+	if c.reached(job.State, job.Health) {
+		fmt.Printf("Job %s: %s/%s\n", c.Job, job.State, job.Health)
+		return nil
+	}
+	if err := c.finished(job.State, job.Health); err != nil {
+		return err
+	}
+
+	var timedOut int32
+	if c.Timeout > 0 {
+		timer := time.AfterFunc(c.Timeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			l.Close()
+		})
+		defer timer.Stop()
+	}
+
+	for {
+		ev, err := l.Next()
+		if atomic.LoadInt32(&timedOut) != 0 {
+			return exitCodeError{code: exitTimeout,
+				msg: fmt.Sprintf("Timeout waiting for job %s to reach state %s", c.Job, c.State)}
+		}
+		if err != nil {
+			return err
+		}
+		if ev.TestJob == nil || !m.match(ev) {
+			continue
+		}
+		if c.reached(ev.TestJob.State, ev.TestJob.Health) {
+			fmt.Printf("Job %s: %s/%s\n", c.Job, ev.TestJob.State, ev.TestJob.Health)
+			return nil
+		}
+		if err := c.finished(ev.TestJob.State, ev.TestJob.Health); err != nil {
+			return err
+		}
+	}
+}
+
+type eventsCmd struct {
+	Listen listenEventsCmd `cmd:"" help:"Print events published by the server"`
+	Wait   waitEventsCmd   `cmd:"" help:"Wait for a job to reach the given state. Exits with 4 on timeout"`
+}
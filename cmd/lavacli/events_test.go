@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestWaitEventsCmd_finished(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    waitEventsCmd
+		state  string
+		health string
+		code   int
+	}{
+		{"still running", waitEventsCmd{State: "finished", Health: "complete"}, "Running", "Unknown", exitOK},
+		{"incomplete", waitEventsCmd{State: "finished", Health: "complete"}, "Finished", "Incomplete", exitIncomplete},
+		{"canceled", waitEventsCmd{State: "running"}, "Finished", "Canceled", exitCanceled},
+		{"complete", waitEventsCmd{State: "finished", Health: "incomplete"}, "Finished", "Complete", exitError},
+	}
+	for _, tt := range tests {
+		err := tt.cmd.finished(tt.state, tt.health)
+		code := exitOK
+		if e, ok := err.(exitCodeError); ok {
+			code = e.code
+		} else if err != nil {
+			t.Errorf("%s: finished() error = %v", tt.name, err)
+		}
+		if code != tt.code {
+			t.Errorf("%s: finished() exit code = %d, want %d", tt.name, code, tt.code)
+		}
+	}
+}
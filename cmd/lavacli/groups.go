@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+type listGroupsCmd struct {
+	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listGroupsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.GroupsList()
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Groups:\n")
+		for _, v := range ret {
+			fmt.Printf("* %s\n", v.Name)
+		}
+	}
+	return nil
+}
+
+type showGroupsCmd struct {
+	Name string `arg:"" required:"" help:"The group to show."`
+	Yaml bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *showGroupsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.GroupsShow(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("id         : %d\n", ret.ID)
+		fmt.Printf("name       : %s\n", ret.Name)
+		fmt.Printf("permissions: %v\n", ret.Permissions)
+		fmt.Printf("users      : %v\n", ret.Users)
+	}
+	return nil
+}
+
+type listGroupPermsCmd struct {
+	Name string `arg:"" required:"" help:"The group name."`
+	Yaml bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listGroupPermsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.GroupsPermsList(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Permissions:\n")
+		for _, v := range ret {
+			fmt.Printf("* %s.%s\n", v.App, v.Codename)
+		}
+	}
+	return nil
+}
+
+type addGroupPermCmd struct {
+	Name     string `arg:"" required:"" help:"The group name."`
+	App      string `arg:"" required:"" help:"The application, e.g. lava_scheduler_app."`
+	Codename string `arg:"" required:"" help:"The permission, e.g. change_device."`
+}
+
+func (c *addGroupPermCmd) Run(ctx *context) error {
+	return ctx.LavaCon.GroupsPermsAdd(c.Name, c.App, c.Codename)
+}
+
+type deleteGroupPermCmd struct {
+	Name     string `arg:"" required:"" help:"The group name."`
+	App      string `arg:"" required:"" help:"The application, e.g. lava_scheduler_app."`
+	Codename string `arg:"" required:"" help:"The permission, e.g. change_device."`
+}
+
+func (c *deleteGroupPermCmd) Run(ctx *context) error {
+	return ctx.LavaCon.GroupsPermsDelete(c.Name, c.App, c.Codename)
+}
+
+type groupPermsCmd struct {
+	List   listGroupPermsCmd  `cmd:"" help:"Lists permissions"`
+	Add    addGroupPermCmd    `cmd:"" help:"Add a permission"`
+	Delete deleteGroupPermCmd `cmd:"" help:"Delete a permission"`
+}
+
+type groupsCmd struct {
+	List  listGroupsCmd `cmd:"" help:"Lists groups"`
+	Show  showGroupsCmd `cmd:"" help:"Show group properties"`
+	Perms groupPermsCmd `cmd:"" help:"Handle group permissions"`
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/siro20/lavacli/pkg/lava"
 )
@@ -28,6 +29,8 @@ type addIdentityCmd struct {
 	Token    string `arg:"" required:"" help:"The authentication token of the user."`
 	Username string `arg:"" required:"" help:"The user to authenticate with."`
 	Proxy    string `arg:"" optional:"" help:"The proxy URI."`
+	Auth     string `flag:"" optional:"" help:"How to send the token: basic, token or bearer." default:"basic" enum:"basic,token,bearer"`
+	API      string `flag:"" optional:"" help:"The API used to talk to the server: xmlrpc or rest." default:"xmlrpc" enum:"xmlrpc,rest"`
 }
 
 func (c *addIdentityCmd) Run(ctx *context) error {
@@ -38,12 +41,25 @@ func (c *addIdentityCmd) Run(ctx *context) error {
 	i.Token = c.Token
 	i.Username = c.Username
 	i.Proxy = c.Proxy
+	if c.Auth != lava.AuthBasic {
+		i.Auth = c.Auth
+	}
+	if c.API != lava.APIXMLRPC {
+		i.API = c.API
+	}
+	// TLS settings and timeout are taken from the global flags
+	i.CACert = ctx.Options.CACert
+	i.ClientCert = ctx.Options.ClientCert
+	i.ClientKey = ctx.Options.ClientKey
+	i.Insecure = ctx.Options.Insecure
+	i.Timeout = int(ctx.Options.Timeout / time.Second)
 
 	return lava.IdentitiesAdd(i)
 }
 
 type showIdentityCmd struct {
-	ID string `arg:"" required:"" help:"The identity to show."`
+	ID          string `arg:"" required:"" help:"The identity to show."`
+	ShowSecrets bool   `flag:"" optional:"" help:"Print the token instead of masking it." default:"false"`
 }
 
 func (c *showIdentityCmd) Run(ctx *context) error {
@@ -55,14 +71,43 @@ func (c *showIdentityCmd) Run(ctx *context) error {
 		fmt.Printf("proxy: %s\n", v.Proxy)
 	}
 	if v.Token != "" {
-		fmt.Printf("token: %s\n", v.Token)
+		if c.ShowSecrets {
+			fmt.Printf("token: %s\n", v.Token)
+		} else {
+			fmt.Printf("token: %s\n", lava.Redact(v.Token, v.Token))
+		}
 	}
 	if v.URI != "" {
-		fmt.Printf("uri: %s\n", v.URI)
+		if c.ShowSecrets {
+			fmt.Printf("uri: %s\n", v.URI)
+		} else {
+			fmt.Printf("uri: %s\n", lava.RedactURI(v.URI))
+		}
 	}
 	if v.Username != "" {
 		fmt.Printf("username: %s\n", v.Username)
 	}
+	if v.CACert != "" {
+		fmt.Printf("ca_cert: %s\n", v.CACert)
+	}
+	if v.ClientCert != "" {
+		fmt.Printf("client_cert: %s\n", v.ClientCert)
+	}
+	if v.ClientKey != "" {
+		fmt.Printf("client_key: %s\n", v.ClientKey)
+	}
+	if v.Insecure {
+		fmt.Printf("insecure: %t\n", v.Insecure)
+	}
+	if v.Timeout != 0 {
+		fmt.Printf("timeout: %d\n", v.Timeout)
+	}
+	if v.Auth != "" {
+		fmt.Printf("auth: %s\n", v.Auth)
+	}
+	if v.API != "" {
+		fmt.Printf("api: %s\n", v.API)
+	}
 	return nil
 }
 
@@ -91,7 +136,7 @@ func (c *deleteIdentityCmd) Run(ctx *context) error {
 
 type identityCmd struct {
 	List   listIdentityCmd   `cmd:"" help:"Lists identities"`
-	Add    addIdentityCmd    `cmd:"" help:"Add an identitiy"`
+	Add    addIdentityCmd    `cmd:"" help:"Add an identitiy. TLS settings and the request timeout are taken from the global flags."`
 	Show   showIdentityCmd   `cmd:"" help:"Show an identitiy"`
 	Delete deleteIdentityCmd `cmd:"" help:"Delete an identitiy"`
 }
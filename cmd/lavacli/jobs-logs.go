@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+// logLevels are the levels used in LAVA job logs
+var logLevels = []string{"target", "feedback", "results", "info", "debug", "error", "warning", "input"}
+
+var logColors = map[string]string{
+	"target":   "\033[32m", // green
+	"feedback": "\033[33m", // yellow
+	"results":  "\033[34m", // blue
+	"error":    "\033[31m", // red
+	"warning":  "\033[35m", // magenta
+	"input":    "\033[36m", // cyan
+	"debug":    "\033[37m", // gray
+}
+
+const colorReset = "\033[0m"
+
+// logOutputFlags control how job logs are printed
+type logOutputFlags struct {
+	Filter []string `flag:"" optional:"" help:"Only print these levels: target, feedback, results, info, debug, error, warning, input"`
+	Color  string   `flag:"" optional:"" help:"Colorize the output: auto, always or never. auto honors NO_COLOR." default:"auto" enum:"auto,always,never"`
+}
+
+// stdoutIsTerminal returns true if stdout is a character device
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// logPrinter prints decoded log lines
+type logPrinter struct {
+	color  bool
+	levels map[string]bool
+}
+
+// newLogPrinter validates the flags and returns a logPrinter
+func (f *logOutputFlags) newLogPrinter() (*logPrinter, error) {
+	p := &logPrinter{}
+
+	switch f.Color {
+	case "always":
+		p.color = true
+	case "never":
+		p.color = false
+	default:
+		// See https://no-color.org/, only a non-empty value disables colors
+		p.color = os.Getenv("NO_COLOR") == "" && stdoutIsTerminal()
+	}
+
+	for _, lvl := range f.Filter {
+		lvl = strings.ToLower(lvl)
+		found := false
+		for _, l := range logLevels {
+			if l == lvl {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown log level %s", lvl)
+		}
+		if p.levels == nil {
+			p.levels = map[string]bool{}
+		}
+		p.levels[lvl] = true
+	}
+
+	return p, nil
+}
+
+// formatResult renders the structured message of the results level
+func formatResult(msg interface{}) (string, bool) {
+	m, ok := msg.(map[interface{}]interface{})
+	if !ok {
+		return "", false
+	}
+	get := func(key string) string {
+		if v, ok := m[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	if get("case") == "" {
+		return "", false
+	}
+
+	line := fmt.Sprintf("%s.%s [%s]", get("definition"), get("case"), get("result"))
+	if measurement := get("measurement"); measurement != "" {
+		line += fmt.Sprintf(" %s %s", measurement, get("units"))
+	}
+	if duration := get("duration"); duration != "" {
+		line += fmt.Sprintf(" (%ss)", duration)
+	}
+
+	return line, true
+}
+
+// shows returns true if lines of the level pass the --filter flag
+func (p *logPrinter) shows(level string) bool {
+	return p.levels == nil || p.levels[level]
+}
+
+// print prints a decoded log line, colorized by its level
+func (p *logPrinter) print(l lava.JobLogsDecoded) {
+	if !p.shows(l.Level) {
+		return
+	}
+
+	msg := fmt.Sprint(l.Message)
+	if l.Level == "results" {
+		if r, ok := formatResult(l.Message); ok {
+			msg = r
+		}
+	}
+
+	color, ok := logColors[l.Level]
+	if p.color && ok {
+		fmt.Printf("%s: %s%s%s\n", l.DateTime, color, msg, colorReset)
+	} else {
+		fmt.Printf("%s: %s\n", l.DateTime, msg)
+	}
+}
+
+type logsJobCmd struct {
+	ID       int           `arg:"" required:"" help:"Job ID"`
+	Raw      bool          `flag:"" optional:"" help:"Print log in raw mode"`
+	Follow   bool          `flag:"" optional:"" help:"Poll for new log lines until the job finished" default:"false"`
+	Interval time.Duration `flag:"" optional:"" help:"Interval between two log fetches in follow mode" default:"5s"`
+	Start    int           `flag:"" optional:"" help:"Print log starting at this line" default:"0"`
+	End      int           `flag:"" optional:"" help:"Print log up to, but not including, this line. 0 prints up to the end." default:"0"`
+	logOutputFlags
+}
+
+// noLogYet returns an empty log if the job hasn't finished yet and the
+// error of the log request otherwise
+func (c *logsJobCmd) noLogYet(ctx *context, logErr error) (*lava.JobsLogs, error) {
+	state, err := ctx.LavaCon.JobsShow(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(state.State, "finished") {
+		return nil, logErr
+	}
+	return &lava.JobsLogs{}, nil
+}
+
+func (c *logsJobCmd) Run(ctx *context) error {
+	printer, err := c.newLogPrinter()
+	if err != nil {
+		return err
+	}
+
+	next := c.Start
+	for {
+		ret, err := ctx.LavaCon.JobsLogsRange(c.ID, next, c.End, c.Raw)
+		if c.Follow && errors.Is(err, lava.ErrNotFound) {
+			// There's no log before the job started
+			ret, err = c.noLogYet(ctx, err)
+		}
+		if err != nil {
+			return err
+		}
+		if c.Raw {
+			fmt.Printf("%s", ret.Data)
+		} else {
+			for i := range ret.Decoded {
+				printer.print(ret.Decoded[i])
+			}
+		}
+		next += ret.Lines
+
+		if !c.Follow || ret.Finished || (c.End > 0 && next >= c.End) {
+			break
+		}
+		time.Sleep(c.Interval)
+	}
+
+	return nil
+}
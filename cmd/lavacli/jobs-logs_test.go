@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lava/lavafake"
+)
+
+func TestFormatResult(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  interface{}
+		want string
+		ok   bool
+	}{
+		{"string", "not a result", "", false},
+		{"no case", map[interface{}]interface{}{"definition": "lava"}, "", false},
+		{"result", map[interface{}]interface{}{"definition": "lava", "case": "boot", "result": "pass"},
+			"lava.boot [pass]", true},
+		{"measurement", map[interface{}]interface{}{"definition": "perf", "case": "iops", "result": "pass",
+			"measurement": 42.5, "units": "ops/s"}, "perf.iops [pass] 42.5 ops/s", true},
+		{"duration", map[interface{}]interface{}{"definition": "lava", "case": "deploy", "result": "fail",
+			"duration": "12.30", "measurement": nil}, "lava.deploy [fail] (12.30s)", true},
+	}
+	for _, tt := range tests {
+		got, ok := formatResult(tt.msg)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: formatResult() = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLogPrinter_filter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  []string
+		level   string
+		want    bool
+		wantErr bool
+	}{
+		{"no filter", nil, "debug", true, false},
+		{"match", []string{"target", "error"}, "error", true, false},
+		{"case insensitive", []string{"TARGET"}, "target", true, false},
+		{"no match", []string{"target", "error"}, "info", false, false},
+		{"unknown level", []string{"verbose"}, "", false, true},
+	}
+	for _, tt := range tests {
+		f := logOutputFlags{Filter: tt.filter, Color: "never"}
+		p, err := f.newLogPrinter()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: newLogPrinter() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && p.shows(tt.level) != tt.want {
+			t.Errorf("%s: shows(%s) = %v, want %v", tt.name, tt.level, !tt.want, tt.want)
+		}
+	}
+}
+
+func TestLogsJobCmd_noLogYet(t *testing.T) {
+	f := lavafake.New()
+	f.DevicesTypesAdd("qemu", lava.DeviceTypeSettings{})
+	if _, err := f.JobsSubmitString("device_type: qemu\njob_name: test\n"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &context{LavaCon: f}
+	logErr := &lava.NotFoundError{Fault: lava.Fault{Code: 404, Message: "Job has no logs"}}
+
+	c := logsJobCmd{ID: 1, Follow: true}
+	logs, err := c.noLogYet(ctx, logErr)
+	if err != nil || logs.Lines != 0 || logs.Finished {
+		t.Errorf("noLogYet() = %+v, %v, want an empty log", logs, err)
+	}
+
+	f.SetJobState(1, "Finished", "Canceled")
+	if _, err = c.noLogYet(ctx, logErr); err != logErr {
+		t.Errorf("noLogYet() error = %v, want the log error", err)
+	}
+
+	c.ID = 42
+	if _, err = c.noLogYet(ctx, logErr); !errors.Is(err, lava.ErrNotFound) || err == logErr {
+		t.Errorf("noLogYet() error = %v, want job not found", err)
+	}
+}
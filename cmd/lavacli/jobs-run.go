@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lavatools"
+)
+
+type runJobCmd struct {
+	Filename string        `arg:"" required:"" help:"File path to local job definition file"`
+	Interval time.Duration `flag:"" optional:"" help:"Interval between two log fetches" default:"5s"`
+	logOutputFlags
+}
+
+// followJob prints new log lines until the job finished. On interrupt the
+// user is asked whether to cancel the job on the server.
+func (c *runJobCmd) followJob(ctx *context, id int, printer *logPrinter) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	next := 0
+	canceled := false
+	for {
+		logs, err := ctx.LavaCon.JobsLogsRange(id, next, 0, false)
+		if err == nil {
+			for i := range logs.Decoded {
+				printer.print(logs.Decoded[i])
+			}
+			next += logs.Lines
+			if logs.Finished {
+				return nil
+			}
+		} else {
+			// The log might not be available yet, check the job instead
+			state, err := ctx.LavaCon.JobsShow(id)
+			if err != nil {
+				return err
+			}
+			if strings.ToLower(state.State) == "finished" {
+				return nil
+			}
+		}
+
+		select {
+		case <-sig:
+			if canceled {
+				return exitCodeError{code: exitError, msg: fmt.Sprintf("Interrupted, job %d is being canceled", id)}
+			}
+			if !confirm(fmt.Sprintf("\nCancel job %d on the server?", id)) {
+				return exitCodeError{code: exitError, msg: fmt.Sprintf("Interrupted, job %d is still running", id)}
+			}
+			err = ctx.LavaCon.JobsCancel(id)
+			if err != nil {
+				return err
+			}
+			canceled = true
+		case <-ticker.C:
+		}
+	}
+}
+
+// printResultsSummary prints the number of test cases per result and all
+// failed test cases. Returns the number of failed test cases.
+func printResultsSummary(results lava.Result) int {
+	count := map[string]int{}
+	var failed []string
+
+	for i := range results {
+		if results[i].Name == "" || results[i].Result == "" {
+			continue
+		}
+		count[results[i].Result]++
+		if results[i].Result == "fail" {
+			failed = append(failed, fmt.Sprintf("%s.%s", results[i].Suite, results[i].Name))
+		}
+	}
+
+	fmt.Printf("Results: %d pass, %d fail, %d skip, %d unknown\n",
+		count["pass"], count["fail"], count["skip"], count["unknown"])
+	for i := range failed {
+		fmt.Printf("* %s [fail]\n", failed[i])
+	}
+
+	return len(failed)
+}
+
+func (c *runJobCmd) Run(ctx *context) error {
+	printer, err := c.newLogPrinter()
+	if err != nil {
+		return err
+	}
+
+	path, err := filepath.Abs(c.Filename)
+	if err != nil {
+		return fmt.Errorf("Failed to resolv path: #%v ", err)
+	}
+	yamlFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read file: #%v ", err)
+	}
+
+	ids, err := ctx.LavaCon.JobsSubmitString(string(yamlFile))
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("Got Invalid jobIDs")
+	}
+	for _, id := range ids {
+		fmt.Printf("Job %d submitted\n", id)
+	}
+
+	// The log of the first multinode sub job is followed, all sub jobs are
+	// waited for and reported
+	err = c.followJob(ctx, ids[0], printer)
+	if err != nil {
+		return err
+	}
+
+	tools, err := lavatools.NewLavaTools(ctx.LavaCon, toolsOptions)
+	if err != nil {
+		return err
+	}
+	code := exitOK
+	failed := 0
+	for _, id := range ids {
+		state, err := tools.WaitForJob(id, c.Interval, 0)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Job %d: %s,%s", id, state.State, state.Health)
+		if state.FailureComment != "" {
+			fmt.Printf(" - %s", state.FailureComment)
+		}
+		fmt.Printf("\n")
+
+		results, err := ctx.LavaCon.Results(id)
+		if err != nil {
+			return err
+		}
+		failed += printResultsSummary(results)
+
+		if ret := jobExitCode(state); ret > code {
+			code = ret
+		}
+	}
+
+	if code == exitOK && failed > 0 {
+		code = exitTestFailure
+	}
+	if code != exitOK {
+		return exitCodeError{code: code}
+	}
+
+	return nil
+}
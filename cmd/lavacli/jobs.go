@@ -6,24 +6,88 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lavatools"
 	"gopkg.in/yaml.v2"
 )
 
 type listJobsCmd struct {
-	YAML   bool   `flag:"" optional:"" help:"Print as YAML" default:"false"`
-	JSON   bool   `flag:"" optional:"" help:"Print as JSON" default:"false"`
-	State  string `flag:"" optional:"" help:"[SUBMITTED, SCHEDULING, SCHEDULED, RUNNING, CANCELING, FINISHED]"`
-	Health string `flag:"" optional:"" help:"[UNKNOWN, COMPLETE, INCOMPLETE, CANCELED]"`
-	Start  int    `flag:"" optional:"" help:"Start at offset" default:"0"`
-	Limit  int    `flag:"" optional:"" help:"Limit to #count jobs" default:"25"`
+	YAML        bool   `flag:"" optional:"" help:"Print as YAML" default:"false"`
+	JSON        bool   `flag:"" optional:"" help:"Print as JSON" default:"false"`
+	State       string `flag:"" optional:"" help:"[SUBMITTED, SCHEDULING, SCHEDULED, RUNNING, CANCELING, FINISHED]"`
+	Health      string `flag:"" optional:"" help:"[UNKNOWN, COMPLETE, INCOMPLETE, CANCELED]"`
+	Start       int    `flag:"" optional:"" help:"Skip the first #count matching jobs" default:"0"`
+	Limit       int    `flag:"" optional:"" help:"Limit to #count jobs" default:"25"`
+	All         bool   `flag:"" optional:"" help:"List all matching jobs, ignores --limit" default:"false"`
+	Submitter   string `flag:"" optional:"" help:"Only list jobs of this submitter"`
+	Mine        bool   `flag:"" optional:"" help:"Only list jobs of the authenticated user" default:"false"`
+	DeviceType  string `flag:"" optional:"" help:"Only list jobs of this device-type"`
+	Since       string `flag:"" optional:"" help:"Only list jobs submitted since, e.g. 2020-12-24, 2020-12-24T18:00:00Z or 48h"`
+	Until       string `flag:"" optional:"" help:"Only list jobs submitted before, e.g. 2020-12-24, 2020-12-24T18:00:00Z or 48h"`
+	Description string `flag:"" optional:"" help:"Only list jobs whose description matches the regular expression"`
+}
+
+// parseTime parses an absolute time or a duration relative to now
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid time %s", s)
+	}
+
+	return time.Now().Add(-d), nil
 }
 
 func (c *listJobsCmd) Run(ctx *context) error {
+	var filter lava.JobsFilter
+	var err error
+
+	filter.State = c.State
+	filter.Health = c.Health
+	filter.Submitter = c.Submitter
+	if c.Mine {
+		filter.Submitter, err = ctx.LavaCon.SystemWhoami()
+		if err != nil {
+			return err
+		}
+	}
+	filter.DeviceType = c.DeviceType
+	if c.Since != "" {
+		filter.SubmittedAfter, err = parseTime(c.Since)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Until != "" {
+		filter.SubmittedBefore, err = parseTime(c.Until)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Description != "" {
+		filter.Description, err = regexp.Compile(c.Description)
+		if err != nil {
+			return err
+		}
+	}
 
-	ret, err := ctx.LavaCon.JobsList(c.State,
-		c.Health, c.Start, c.Limit)
+	limit := c.Limit
+	if c.All {
+		limit = 0
+	}
+	ret, err := ctx.LavaCon.JobsListFiltered(filter, c.Start, limit)
 	if err != nil {
 		return err
 	}
@@ -41,7 +105,11 @@ func (c *listJobsCmd) Run(ctx *context) error {
 		}
 		fmt.Println(string(d))
 	} else {
-		fmt.Printf("Jobs (from %d to %d):\n", c.Start+1, c.Limit)
+		if c.All {
+			fmt.Printf("Jobs (from %d):\n", c.Start+1)
+		} else {
+			fmt.Printf("Jobs (from %d to %d):\n", c.Start+1, c.Limit)
+		}
 		for _, v := range ret {
 			fmt.Printf("* %d: %s,%s [%s] (%s) - %s\n", v.ID, v.State, v.Health, v.Submitter, v.Description, v.DeviceType)
 		}
@@ -49,6 +117,47 @@ func (c *listJobsCmd) Run(ctx *context) error {
 	return nil
 }
 
+type queueJobsCmd struct {
+	YAML       bool     `flag:"" optional:"" help:"Print as YAML" default:"false"`
+	JSON       bool     `flag:"" optional:"" help:"Print as JSON" default:"false"`
+	DeviceType []string `flag:"" optional:"" help:"Only show jobs for this device-type. Can be repeated."`
+	Start      int      `flag:"" optional:"" help:"Start at offset" default:"0"`
+	Limit      int      `flag:"" optional:"" help:"Limit to #count jobs" default:"25"`
+}
+
+func (c *queueJobsCmd) Run(ctx *context) error {
+
+	ret, err := ctx.LavaCon.JobsQueue(c.DeviceType, c.Start, c.Limit)
+	if err != nil {
+		return err
+	}
+
+	if c.YAML {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Queue (from %d to %d):\n", c.Start+1, c.Limit)
+		for _, v := range ret {
+			id := strconv.Itoa(v.ID)
+			if v.SubID != "" {
+				id = v.SubID
+			}
+			fmt.Printf("* %s: %s [%s] prio %d, tags %v, queued for %s - %s\n", id, v.RequestedDeviceType,
+				v.Submitter, v.Priority, v.Tags, time.Since(v.SubmitTime).Round(time.Second), v.Description)
+		}
+	}
+	return nil
+}
+
 type showJobCmd struct {
 	YAML bool `flag:"" optional:"" help:"Print as YAML" default:"false"`
 	JSON bool `flag:"" optional:"" help:"Print as JSON" default:"false"`
@@ -159,79 +268,242 @@ func (c *submitJobCmd) Run(ctx *context) error {
 	return nil
 }
 
+// jobSelectorFlags select jobs for bulk operations
+type jobSelectorFlags struct {
+	IDs        []string      `arg:"" optional:"" name:"ids" help:"Job IDs or ranges of job IDs like 100-200"`
+	State      string        `flag:"" optional:"" help:"[SUBMITTED, SCHEDULING, SCHEDULED, RUNNING, CANCELING, FINISHED]"`
+	Health     string        `flag:"" optional:"" help:"[UNKNOWN, COMPLETE, INCOMPLETE, CANCELED]"`
+	Submitter  string        `flag:"" optional:"" help:"Only select jobs of this submitter"`
+	DeviceType string        `flag:"" optional:"" help:"Only select jobs of this device-type"`
+	OlderThan  time.Duration `flag:"" optional:"" help:"Only select jobs submitted at least this long ago, e.g. 24h"`
+	DryRun     bool          `flag:"" optional:"" help:"Only print the selected jobs" default:"false"`
+	Parallel   int           `flag:"" optional:"" help:"Number of concurrent operations" default:"4"`
+}
+
+// runBulk selects the jobs and runs op on them, printing a per job summary
+func (f *jobSelectorFlags) runBulk(ctx *context, verb string,
+	op func(tools lavatools.Lavatools, ids []int, parallel int) []lavatools.JobOperationResult) error {
+	var sel lavatools.JobSelector
+
+	if len(f.IDs) == 0 && f.State == "" && f.Health == "" && f.Submitter == "" {
+		return fmt.Errorf("Must specify job IDs, --state, --health or --submitter")
+	}
+	for _, s := range f.IDs {
+		r, err := lavatools.ParseIDRange(s)
+		if err != nil {
+			return err
+		}
+		sel.IDs = append(sel.IDs, r)
+	}
+	sel.State = f.State
+	sel.Health = f.Health
+	sel.Submitter = f.Submitter
+	sel.DeviceType = f.DeviceType
+	sel.OlderThan = f.OlderThan
+
+	tools, err := lavatools.NewLavaTools(ctx.LavaCon, toolsOptions)
+	if err != nil {
+		return err
+	}
+	jobs, results, err := tools.SelectJobs(sel, f.Parallel)
+	if err != nil {
+		return err
+	}
+
+	if f.DryRun {
+		fmt.Printf("Would %s %d jobs:\n", verb, len(jobs))
+		for _, v := range jobs {
+			fmt.Printf("* %d: %s,%s [%s] (%s) - %s\n", v.ID, v.State, v.Health, v.Submitter, v.Description, v.DeviceType)
+		}
+		for _, r := range results {
+			fmt.Printf("* %d: %v\n", r.ID, r.Err)
+		}
+		if len(results) > 0 {
+			return fmt.Errorf("Failed to look up %d jobs: %w", len(results), results[0].Err)
+		}
+		return nil
+	}
+
+	var ids []int
+	for _, v := range jobs {
+		ids = append(ids, v.ID)
+	}
+	// Jobs which failed to be looked up are reported as failed
+	results = append(results, op(tools, ids, f.Parallel)...)
+	// A single job fails like the command used to before it accepted selectors
+	single := len(sel.IDs) == 1 && sel.IDs[0].From == sel.IDs[0].To
+	failed := 0
+	var firstErr error
+	for _, r := range results {
+		if r.Err != nil {
+			if !single {
+				fmt.Printf("* %d: %v\n", r.ID, r.Err)
+			}
+			if failed == 0 {
+				firstErr = r.Err
+			}
+			failed++
+		} else if len(r.NewIDs) > 0 {
+			// Multinode jobs are resubmitted as a whole
+			for _, id := range r.NewIDs {
+				fmt.Printf("* %d -> %d\n", r.ID, id)
+			}
+		} else {
+			fmt.Printf("* %d: ok\n", r.ID)
+		}
+	}
+	if failed > 0 && single {
+		return firstErr
+	} else if failed > 0 {
+		// The first error selects the exit code
+		return fmt.Errorf("Failed to %s %d of %d jobs: %w", verb, failed, len(results), firstErr)
+	}
+
+	return nil
+}
+
 type cancelJobCmd struct {
-	ID int `arg:"" required:"" help:"Job ID"`
+	jobSelectorFlags
 }
 
 func (c *cancelJobCmd) Run(ctx *context) error {
-	return ctx.LavaCon.JobsCancel(c.ID)
+	return c.runBulk(ctx, "cancel", lavatools.Lavatools.CancelJobs)
+}
+
+type failJobCmd struct {
+	jobSelectorFlags
+}
+
+func (c *failJobCmd) Run(ctx *context) error {
+	return c.runBulk(ctx, "fail", lavatools.Lavatools.FailJobs)
+}
+
+type resubmitJobCmd struct {
+	jobSelectorFlags
 }
 
-type logsJobCmd struct {
-	ID  int  `arg:"" required:"" help:"Job ID"`
-	Raw bool `flag:"" optional:"" help:"Print log in raw mode"`
+func (c *resubmitJobCmd) Run(ctx *context) error {
+	return c.runBulk(ctx, "resubmit", lavatools.Lavatools.ResubmitJobs)
 }
 
-func (c *logsJobCmd) Run(ctx *context) error {
-	var Reset = "\033[0m"
-	var Red = "\033[31m"
-	var Green = "\033[32m"
-	var Yellow = "\033[33m"
-	var Blue = "\033[34m"
-	var Gray = "\033[37m"
+type configJobCmd struct {
+	ID   int    `arg:"" required:"" help:"Job ID"`
+	Dest string `flag:"" optional:"" help:"Destination directory" default:"."`
+}
 
-	ret, err := ctx.LavaCon.JobsLogs(c.ID, c.Raw)
+func (c *configJobCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.JobsConfiguration(c.ID)
 	if err != nil {
 		return err
 	}
-	if c.Raw {
-		fmt.Printf("%s\n", ret.Data)
-	} else {
-		for i := range ret.Decoded {
-			lvl := ret.Decoded[i].Level
-			colorStart := ""
-			colorStop := ""
-			if lvl == "feedback" {
-				lvl = ""
-				colorStart = Yellow
-				colorStop = Reset
-			} else if lvl == "target" {
-				lvl = ""
-				colorStart = Green
-				colorStop = Reset
-			} else if lvl == "results" {
-				lvl = ""
-				colorStart = Blue
-				colorStop = Reset
-			} else if lvl == "error" {
-				lvl = ""
-				colorStart = Red
-				colorStop = Reset
-			} else if lvl == "debug" {
-				lvl = ""
-				colorStart = Gray
-				colorStop = Reset
-			}
-			fmt.Printf("%s: %s %s %s\n", ret.Decoded[i].DateTime,
-				colorStart,
-				ret.Decoded[i].Message,
-				colorStop)
+
+	err = os.MkdirAll(c.Dest, 0755)
+	if err != nil {
+		return err
+	}
+
+	docs := []struct {
+		name string
+		data string
+	}{
+		{"definition.yaml", ret.Definition},
+		{"device.yaml", ret.Device},
+		{"dispatcher.yaml", ret.Dispatcher},
+		{"env.yaml", ret.Env},
+		{"env-dut.yaml", ret.EnvDut},
+	}
+	for _, doc := range docs {
+		if doc.data == "" {
+			continue
+		}
+		path := filepath.Join(c.Dest, doc.name)
+		err = ioutil.WriteFile(path, []byte(doc.data), 0644)
+		if err != nil {
+			return fmt.Errorf("Failed to write file: #%v ", err)
 		}
+		fmt.Println(path)
 	}
 
-	//for k, v := range ret {
-	//	fmt.Printf("%s: %v\n", k, v)
-	//}
+	return nil
+}
+
+// jobExitCode maps the health of a finished job to the CLI exit code
+func jobExitCode(state *lava.JobState) int {
+	switch strings.ToLower(state.Health) {
+	case "complete":
+		return exitOK
+	case "incomplete":
+		return exitIncomplete
+	case "canceled":
+		return exitCanceled
+	}
+	return exitError
+}
+
+type waitJobCmd struct {
+	IDs      []int         `arg:"" required:"" name:"ids" help:"Job IDs"`
+	Interval time.Duration `flag:"" optional:"" help:"Interval between two job state checks" default:"10s"`
+	Timeout  time.Duration `flag:"" optional:"" help:"Maximum time to wait for all jobs. 0 waits forever." default:"0"`
+}
+
+func (c *waitJobCmd) Run(ctx *context) error {
+	tools, err := lavatools.NewLavaTools(ctx.LavaCon, toolsOptions)
+	if err != nil {
+		return err
+	}
+
+	code := exitOK
+	start := time.Now()
+	for _, id := range c.IDs {
+		timeout := time.Duration(0)
+		if c.Timeout > 0 {
+			timeout = c.Timeout - time.Since(start)
+			if timeout <= 0 {
+				timeout = time.Nanosecond
+			}
+		}
+
+		state, err := tools.WaitForJob(id, c.Interval, timeout)
+		if err == lavatools.ErrWaitTimeout {
+			fmt.Printf("* %d: %s,%s - timeout\n", id, state.State, state.Health)
+			code = exitTimeout
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		fmt.Printf("* %d: %s,%s [%s]", id, state.State, state.Health, state.Device)
+		// Jobs canceled before they started have no start time
+		if !state.StartTime.IsZero() {
+			fmt.Printf(" %s", state.EndTime.Sub(state.StartTime).Round(time.Second))
+		}
+		if state.FailureComment != "" {
+			fmt.Printf(" - %s", state.FailureComment)
+		}
+		fmt.Printf("\n")
+		if ret := jobExitCode(state); ret > code {
+			code = ret
+		}
+	}
+	if code != exitOK {
+		return exitCodeError{code: code}
+	}
 
 	return nil
 }
 
 type jobsCmd struct {
 	List       listJobsCmd      `cmd:"" help:"Lists jobs"`
+	Queue      queueJobsCmd     `cmd:"" help:"Lists queued jobs"`
 	Show       showJobCmd       `cmd:"" help:"Show job details"`
 	Definition definitionJobCmd `cmd:"" help:"Handle job definition"`
+	Config     configJobCmd     `cmd:"" help:"Get the dispatcher configuration of a job"`
 	Validate   validateJobCmd   `cmd:"" help:"Validate job definition"`
 	Submit     submitJobCmd     `cmd:"" help:"Submit new job"`
-	Cancel     cancelJobCmd     `cmd:"" help:"Cancel running job"`
+	Run        runJobCmd        `cmd:"" help:"Submit new job, follow the log and report the results. Exits like wait or with 5 if a test failed"`
+	Cancel     cancelJobCmd     `cmd:"" help:"Cancel jobs"`
+	Fail       failJobCmd       `cmd:"" help:"Fail jobs"`
+	Resubmit   resubmitJobCmd   `cmd:"" help:"Resubmit jobs"`
 	Logs       logsJobCmd       `cmd:"" help:"Show job log"`
+	Wait       waitJobCmd       `cmd:"" help:"Wait for jobs to finish. Exits with 2 if a job is incomplete, 3 if canceled and 4 on timeout"`
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lava/lavafake"
+)
+
+func TestResubmitJobCmd_single(t *testing.T) {
+	f := lavafake.New()
+	f.DevicesTypesAdd("qemu", lava.DeviceTypeSettings{})
+	if _, err := f.JobsSubmitString("device_type: qemu\njob_name: test\n"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &context{LavaCon: f}
+
+	c := resubmitJobCmd{jobSelectorFlags{IDs: []string{"1"}, Parallel: 1}}
+	if err := c.Run(ctx); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if n := f.Calls("JobsResubmit"); n != 1 {
+		t.Errorf("JobsResubmit called %d times, want 1", n)
+	}
+
+	// A missing job fails with its own error and exit code
+	c = resubmitJobCmd{jobSelectorFlags{IDs: []string{"42"}, Parallel: 1}}
+	err := c.Run(ctx)
+	if !errors.Is(err, lava.ErrNotFound) {
+		t.Fatalf("Run() error = %v, want ErrNotFound", err)
+	}
+	if e, ok := lavaExitCode(err); !ok || e.code != exitNotFound {
+		t.Errorf("lavaExitCode() = %d, want %d", e.code, exitNotFound)
+	}
+}
@@ -3,22 +3,37 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lavatools"
 )
 
+// toolsOptions are used by commands relying on lavatools. The CLI is short
+// lived, so there's no point in prefetching data in the background.
+var toolsOptions = lavatools.Options{
+	RetryCount:            lavatools.DefaultOptions.RetryCount,
+	PollInterval:          lavatools.DefaultOptions.PollInterval,
+	InvalidTimeout:        lavatools.DefaultOptions.InvalidTimeout,
+	BackgroundPrefetching: false,
+}
+
 func connect(ctx *context) (c *lava.Connection, err error) {
 
 	if ctx.URI != "" {
-		c, err = lava.ConnectByURI(ctx.URI, ctx.Proxy, lava.DefaultOptions)
+		c, err = lava.ConnectByURI(ctx.URI, ctx.Proxy, ctx.Options)
 		if err != nil {
-			err = fmt.Errorf("failed to connect by using URI %s: %v", ctx.URI, err)
+			err = fmt.Errorf("failed to connect by using URI %s: %v", lava.RedactURI(ctx.URI), err)
 			return
 		}
 	} else {
-		c, err = lava.ConnectByConfigID(ctx.Profile, lava.DefaultOptions)
+		c, err = lava.ConnectByConfigID(ctx.Profile, ctx.Options)
 		if err != nil {
 			err = fmt.Errorf("failed to connect by using identity %s: %v", ctx.Profile, err)
 			return
@@ -28,11 +43,98 @@ func connect(ctx *context) (c *lava.Connection, err error) {
 	return
 }
 
+// Exit codes of the CLI
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitIncomplete  = 2
+	exitCanceled    = 3
+	exitTimeout     = 4
+	exitTestFailure = 5
+	// Errors returned by the LAVA server
+	exitNotFound         = 6
+	exitPermissionDenied = 7
+	exitInvalidRequest   = 8
+	exitServerError      = 9
+	exitTransportError   = 10
+)
+
+// exitCodeError makes the CLI exit with the given code.
+// The message is printed unless empty.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e exitCodeError) Error() string {
+	return e.msg
+}
+
+// lavaExitCode maps the typed errors of pkg/lava to exit codes and friendly messages
+func lavaExitCode(err error) (exitCodeError, bool) {
+	var notFound *lava.NotFoundError
+	var denied *lava.PermissionDeniedError
+	var invalid *lava.InvalidRequestError
+	var server *lava.ServerError
+	var transport *lava.TransportError
+
+	switch {
+	case errors.As(err, &notFound):
+		return exitCodeError{code: exitNotFound,
+			msg: fmt.Sprintf("Not found: %s", describe(err, notFound, notFound.Message))}, true
+	case errors.As(err, &denied):
+		return exitCodeError{code: exitPermissionDenied,
+			msg: fmt.Sprintf("Permission denied: %s. Check the username and token of the identity.",
+				describe(err, denied, denied.Message))}, true
+	case errors.As(err, &invalid):
+		return exitCodeError{code: exitInvalidRequest,
+			msg: fmt.Sprintf("Invalid request: %s", describe(err, invalid, invalid.Message))}, true
+	case errors.As(err, &server):
+		return exitCodeError{code: exitServerError,
+			msg: fmt.Sprintf("The LAVA server failed to handle the request: %s", describe(err, server, server.Message))}, true
+	case errors.As(err, &transport):
+		return exitCodeError{code: exitTransportError,
+			msg: fmt.Sprintf("Unable to reach the LAVA server: %v", err)}, true
+	}
+
+	return exitCodeError{}, false
+}
+
+// describe returns the message of err, replacing the message of the wrapped
+// error target by msg. Context added while wrapping, like "Job 123: ", is kept.
+func describe(err error, target error, msg string) string {
+	return strings.Replace(err.Error(), target.Error(), msg, 1)
+}
+
+// optionalBool converts a pair of mutually exclusive flags into an optional bool.
+// Returns nil if none of the flags is set.
+func optionalBool(set bool, unset bool) (*bool, error) {
+	if set && unset {
+		return nil, fmt.Errorf("Flags are mutually exclusive")
+	} else if set || unset {
+		return &set, nil
+	}
+	return nil, nil
+}
+
+// confirm asks the user a yes/no question on stdin. Defaults to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
 type context struct {
 	Profile string
 	URI     string
 	Proxy   string
-	LavaCon *lava.Connection
+	Options lava.ConnectionOptions
+	LavaCon lava.Client
 }
 
 var cli struct {
@@ -40,11 +142,24 @@ var cli struct {
 	URI     string `help:"URI of the lava-server RPC endpoint. Default:Read from config."`
 	Proxy   string `help:"Proxy to use when connecting. Default:Read from config."`
 
+	CACert     string        `name:"ca-cert" help:"PEM file holding additional CAs to trust. Default:Read from config."`
+	ClientCert string        `help:"PEM file holding the client certificate. Default:Read from config."`
+	ClientKey  string        `help:"PEM file holding the client key. Default:Read from config."`
+	Insecure   bool          `help:"Do not verify the server certificate. Default:Read from config."`
+	Timeout    time.Duration `name:"request-timeout" help:"Timeout of a single request. Default:Read from config."`
+	Debug      bool          `help:"Log all requests to stderr. Tokens are masked."`
+
 	Identities  identityCmd    `cmd:"" help:"Deals with identities in lavacli.yaml"`
 	Devices     devicesCmd     `cmd:"" help:"Configure devices on the LAVA server."`
 	Jobs        jobsCmd        `cmd:"" help:"Configure jobs on the LAVA server."`
 	Results     resultsCmd     `cmd:"" help:"Get results on the LAVA server."`
 	DeviceTypes deviceTypesCmd `cmd:"" help:"Configure device types on the LAVA server."`
+	Workers     workersCmd     `cmd:"" help:"Configure workers on the LAVA server."`
+	Tags        tagsCmd        `cmd:"" help:"Configure tags on the LAVA server."`
+	Users       usersCmd       `cmd:"" help:"Configure users on the LAVA server."`
+	Groups      groupsCmd      `cmd:"" help:"Configure groups on the LAVA server."`
+	System      systemCmd      `cmd:"" help:"Query system information of the LAVA server."`
+	Events      eventsCmd      `cmd:"" help:"Listen to events published by the LAVA server."`
 }
 
 func main() {
@@ -58,9 +173,20 @@ func main() {
 			Tree:    true,
 		}))
 
+	options := lava.DefaultOptions
+	options.CACert = cli.CACert
+	options.ClientCert = cli.ClientCert
+	options.ClientKey = cli.ClientKey
+	options.Insecure = cli.Insecure
+	options.Timeout = cli.Timeout
+	if cli.Debug {
+		options.Debug = os.Stderr
+	}
+
 	myCtx := context{Profile: cli.Profile,
-		URI:   cli.URI,
-		Proxy: cli.Proxy}
+		URI:     cli.URI,
+		Proxy:   cli.Proxy,
+		Options: options}
 
 	if ctx.Command() != "identities" {
 		myCtx.LavaCon, err = connect(&myCtx)
@@ -69,5 +195,15 @@ func main() {
 	// Call the Run() method of the selected parsed command.
 	err = ctx.Run(&myCtx)
 
+	var exit exitCodeError
+	if !errors.As(err, &exit) {
+		exit, _ = lavaExitCode(err)
+	}
+	if exit.code != exitOK {
+		if exit.msg != "" {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", ctx.Model.Name, exit.msg)
+		}
+		os.Exit(exit.code)
+	}
 	ctx.FatalIfErrorf(err)
 }
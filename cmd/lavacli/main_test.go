@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func TestLavaExitCode(t *testing.T) {
+	notFound := &lava.NotFoundError{Fault: lava.Fault{Code: 404, Message: "Job not found"}}
+	tests := []struct {
+		err  error
+		code int
+		msg  string
+	}{
+		{notFound, exitNotFound, "Not found: Job not found"},
+		{fmt.Errorf("Job 123: %w", notFound), exitNotFound, "Not found: Job 123: Job not found"},
+		{fmt.Errorf("Device qemu01: %w", &lava.PermissionDeniedError{Fault: lava.Fault{Code: 403, Message: "Forbidden"}}),
+			exitPermissionDenied, "Permission denied: Device qemu01: Forbidden. Check the username and token of the identity."},
+		{fmt.Errorf("Job 123: %w", &lava.TransportError{Err: errors.New("connection refused")}),
+			exitTransportError, "Unable to reach the LAVA server: Job 123: connection refused"},
+	}
+	for _, tt := range tests {
+		got, ok := lavaExitCode(tt.err)
+		if !ok || got.code != tt.code || got.msg != tt.msg {
+			t.Errorf("lavaExitCode(%v) = %d, %q, want %d, %q", tt.err, got.code, got.msg, tt.code, tt.msg)
+		}
+	}
+	if _, ok := lavaExitCode(errors.New("other")); ok {
+		t.Errorf("lavaExitCode() mapped an untyped error")
+	}
+}
@@ -4,30 +4,76 @@ package main
 
 import (
 	"fmt"
+
+	"github.com/siro20/lavacli/pkg/lava"
 )
 
 type resultsShowCmd struct {
-	ID   int  `arg:"" required:"" help:"Job ID"`
-	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
-	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+	ID    int    `arg:"" required:"" help:"Job ID"`
+	Suite string `arg:"" optional:"" help:"Only show results of this test suite"`
+	Case  string `arg:"" optional:"" help:"Only show results of this test case. Requires a test suite."`
+	Yaml  bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON  bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+	CSV   bool   `flag:"" optional:"" help:"Output as CSV" default:"false"`
 }
 
 func (c *resultsShowCmd) Run(ctx *context) error {
+	if c.Case != "" && c.Suite == "" {
+		return fmt.Errorf("Must specify a test suite")
+	}
 
 	if c.Yaml {
-		ret, err := ctx.LavaCon.ResultsAsYAML(c.ID)
+		var ret string
+		var err error
+		if c.Case != "" {
+			ret, err = ctx.LavaCon.ResultsCaseAsYAML(c.ID, c.Suite, c.Case)
+		} else if c.Suite != "" {
+			ret, err = ctx.LavaCon.ResultsSuiteAsYAML(c.ID, c.Suite)
+		} else {
+			ret, err = ctx.LavaCon.ResultsAsYAML(c.ID)
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Printf(ret)
+		fmt.Print(ret)
 	} else if c.JSON {
-		ret, err := ctx.LavaCon.ResultsAsJSON(c.ID)
+		var ret string
+		var err error
+		if c.Case != "" {
+			ret, err = ctx.LavaCon.ResultsCaseAsJSON(c.ID, c.Suite, c.Case)
+		} else if c.Suite != "" {
+			ret, err = ctx.LavaCon.ResultsSuiteAsJSON(c.ID, c.Suite)
+		} else {
+			ret, err = ctx.LavaCon.ResultsAsJSON(c.ID)
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Printf(ret)
+		fmt.Print(ret)
+	} else if c.CSV {
+		var ret string
+		var err error
+		if c.Case != "" {
+			ret, err = ctx.LavaCon.ResultsCaseAsCSV(c.ID, c.Suite, c.Case)
+		} else if c.Suite != "" {
+			ret, err = ctx.LavaCon.ResultsSuiteAsCSV(c.ID, c.Suite)
+		} else {
+			ret, err = ctx.LavaCon.ResultsAsCSV(c.ID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(ret)
 	} else {
-		ret, err := ctx.LavaCon.Results(c.ID)
+		var ret lava.Result
+		var err error
+		if c.Case != "" {
+			ret, err = ctx.LavaCon.ResultsCase(c.ID, c.Suite, c.Case)
+		} else if c.Suite != "" {
+			ret, err = ctx.LavaCon.ResultsSuite(c.ID, c.Suite)
+		} else {
+			ret, err = ctx.LavaCon.Results(c.ID)
+		}
 		if err != nil {
 			return err
 		}
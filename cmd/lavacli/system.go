@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type versionSystemCmd struct {
+}
+
+func (c *versionSystemCmd) Run(ctx *context) error {
+	version, err := ctx.LavaCon.SystemVersion()
+	if err != nil {
+		return err
+	}
+	api, err := ctx.LavaCon.SystemAPIVersion()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version    : %s\n", version)
+	fmt.Printf("api version: %d\n", api)
+
+	return nil
+}
+
+type whoamiSystemCmd struct {
+}
+
+func (c *whoamiSystemCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.SystemWhoami()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ret)
+
+	return nil
+}
+
+type listMethodsCmd struct {
+}
+
+func (c *listMethodsCmd) Run(ctx *context, m *methodsCmd) error {
+	ret, err := ctx.LavaCon.SystemListMethods()
+	if err != nil {
+		return err
+	}
+
+	if m.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if m.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Methods:\n")
+		for i := range ret {
+			fmt.Printf("* %s\n", ret[i])
+		}
+	}
+	return nil
+}
+
+type helpMethodsCmd struct {
+	Name string `arg:"" required:"" help:"The method name."`
+}
+
+func (c *helpMethodsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.SystemMethodHelp(c.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ret)
+
+	return nil
+}
+
+type signatureMethodsCmd struct {
+	Name string `arg:"" required:"" help:"The method name."`
+}
+
+func (c *signatureMethodsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.SystemMethodSignature(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if len(ret) == 0 {
+		fmt.Println("undef")
+	}
+	for _, sig := range ret {
+		if len(sig) == 0 {
+			continue
+		}
+		fmt.Printf("%s(%s) -> %s\n", c.Name, strings.Join(sig[1:], ", "), sig[0])
+	}
+
+	return nil
+}
+
+type methodsCmd struct {
+	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+
+	List      listMethodsCmd      `cmd:"" default:"1" help:"Lists available methods (default)"`
+	Help      helpMethodsCmd      `cmd:"" help:"Show method documentation"`
+	Signature signatureMethodsCmd `cmd:"" help:"Show method signature"`
+}
+
+type systemCmd struct {
+	Version versionSystemCmd `cmd:"" help:"Show the server version"`
+	Whoami  whoamiSystemCmd  `cmd:"" help:"Show the authenticated user"`
+	Methods methodsCmd       `cmd:"" help:"List server methods or show their documentation"`
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+type listTagsCmd struct {
+	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listTagsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.TagsList()
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Tags:\n")
+		for _, v := range ret {
+			if v.Description != "" {
+				fmt.Printf("* %s (%s)\n", v.Name, v.Description)
+			} else {
+				fmt.Printf("* %s\n", v.Name)
+			}
+		}
+	}
+	return nil
+}
+
+type showTagsCmd struct {
+	Name string `arg:"" required:"" help:"The tag to show."`
+	Yaml bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *showTagsCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.TagsShow(c.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("name       : %s\n", ret.Name)
+		fmt.Printf("description: %s\n", ret.Description)
+		fmt.Printf("devices    : %v\n", ret.Devices)
+	}
+	return nil
+}
+
+type addTagsCmd struct {
+	Name        string `arg:"" required:"" help:"The tag to add."`
+	Description string `flag:"" optional:"" help:"Description of the tag."`
+}
+
+func (c *addTagsCmd) Run(ctx *context) error {
+	return ctx.LavaCon.TagsAdd(c.Name, c.Description)
+}
+
+type deleteTagsCmd struct {
+	Name string `arg:"" required:"" help:"The tag to delete."`
+}
+
+func (c *deleteTagsCmd) Run(ctx *context) error {
+	return ctx.LavaCon.TagsDelete(c.Name)
+}
+
+type tagsCmd struct {
+	List   listTagsCmd   `cmd:"" help:"Lists tags"`
+	Show   showTagsCmd   `cmd:"" help:"Show tag properties"`
+	Add    addTagsCmd    `cmd:"" help:"Add a tag"`
+	Delete deleteTagsCmd `cmd:"" help:"Delete a tag"`
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"gopkg.in/yaml.v2"
+)
+
+type listUsersCmd struct {
+	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listUsersCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.UsersList()
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Users:\n")
+		for _, v := range ret {
+			fmt.Printf("* %s (%s %s)\n", v.Username, v.FirstName, v.LastName)
+		}
+	}
+	return nil
+}
+
+type showUsersCmd struct {
+	Username string `arg:"" required:"" help:"The user to show."`
+	Yaml     bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON     bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *showUsersCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.UsersShow(c.Username)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("id         : %d\n", ret.ID)
+		fmt.Printf("username   : %s\n", ret.Username)
+		fmt.Printf("first name : %s\n", ret.FirstName)
+		fmt.Printf("last name  : %s\n", ret.LastName)
+		fmt.Printf("email      : %s\n", ret.Email)
+		fmt.Printf("active     : %v\n", ret.IsActive)
+		fmt.Printf("staff      : %v\n", ret.IsStaff)
+		fmt.Printf("superuser  : %v\n", ret.IsSuperuser)
+		fmt.Printf("groups     : %v\n", ret.Groups)
+		fmt.Printf("permissions: %v\n", ret.Permissions)
+		fmt.Printf("last login : %s\n", ret.LastLogin)
+		fmt.Printf("joined     : %s\n", ret.DateJoined)
+	}
+	return nil
+}
+
+type addUsersCmd struct {
+	Username  string `arg:"" required:"" help:"The user to add."`
+	FirstName string `flag:"" optional:"" help:"First name of the user."`
+	LastName  string `flag:"" optional:"" help:"Last name of the user."`
+	Email     string `flag:"" optional:"" help:"Email address of the user."`
+	Inactive  bool   `flag:"" optional:"" help:"Add the user as inactive." default:"false"`
+	Staff     bool   `flag:"" optional:"" help:"Add the user as staff." default:"false"`
+	Superuser bool   `flag:"" optional:"" help:"Add the user as superuser." default:"false"`
+}
+
+func (c *addUsersCmd) Run(ctx *context) error {
+	active := !c.Inactive
+
+	return ctx.LavaCon.UsersAdd(c.Username, lava.UserSettings{
+		FirstName:   c.FirstName,
+		LastName:    c.LastName,
+		Email:       c.Email,
+		IsActive:    &active,
+		IsStaff:     &c.Staff,
+		IsSuperuser: &c.Superuser,
+	})
+}
+
+type updateUsersCmd struct {
+	Username    string `arg:"" required:"" help:"The user to update."`
+	FirstName   string `flag:"" optional:"" help:"First name of the user. Default:Unchanged."`
+	LastName    string `flag:"" optional:"" help:"Last name of the user. Default:Unchanged."`
+	Email       string `flag:"" optional:"" help:"Email address of the user. Default:Unchanged."`
+	Active      bool   `flag:"" optional:"" help:"Activate the user." default:"false"`
+	Inactive    bool   `flag:"" optional:"" help:"Deactivate the user." default:"false"`
+	Staff       bool   `flag:"" optional:"" help:"Make the user staff." default:"false"`
+	NoStaff     bool   `flag:"" optional:"" help:"Remove the staff status." default:"false"`
+	Superuser   bool   `flag:"" optional:"" help:"Make the user superuser." default:"false"`
+	NoSuperuser bool   `flag:"" optional:"" help:"Remove the superuser status." default:"false"`
+}
+
+func (c *updateUsersCmd) Run(ctx *context) error {
+	var s lava.UserSettings
+	var err error
+
+	s.FirstName = c.FirstName
+	s.LastName = c.LastName
+	s.Email = c.Email
+	s.IsActive, err = optionalBool(c.Active, c.Inactive)
+	if err != nil {
+		return fmt.Errorf("--active and --inactive: %v", err)
+	}
+	s.IsStaff, err = optionalBool(c.Staff, c.NoStaff)
+	if err != nil {
+		return fmt.Errorf("--staff and --no-staff: %v", err)
+	}
+	s.IsSuperuser, err = optionalBool(c.Superuser, c.NoSuperuser)
+	if err != nil {
+		return fmt.Errorf("--superuser and --no-superuser: %v", err)
+	}
+
+	return ctx.LavaCon.UsersUpdate(c.Username, s)
+}
+
+type deleteUsersCmd struct {
+	Username string `arg:"" required:"" help:"The user to delete."`
+}
+
+func (c *deleteUsersCmd) Run(ctx *context) error {
+	return ctx.LavaCon.UsersDelete(c.Username)
+}
+
+type usersCmd struct {
+	List   listUsersCmd   `cmd:"" help:"Lists users"`
+	Show   showUsersCmd   `cmd:"" help:"Show user properties"`
+	Add    addUsersCmd    `cmd:"" help:"Add a user"`
+	Update updateUsersCmd `cmd:"" help:"Update user properties"`
+	Delete deleteUsersCmd `cmd:"" help:"Delete a user"`
+}
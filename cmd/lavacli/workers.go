@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type listWorkersCmd struct {
+	Yaml bool `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON bool `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *listWorkersCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.WorkersList()
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("Workers:\n")
+		for _, v := range ret {
+			fmt.Printf("* %s\n", v.Hostname)
+		}
+	}
+	return nil
+}
+
+type showWorkersCmd struct {
+	Hostname string `arg:"" required:"" help:"The worker to show."`
+	Yaml     bool   `flag:"" optional:"" help:"Output as YAML" default:"false"`
+	JSON     bool   `flag:"" optional:"" help:"Output as JSON" default:"false"`
+}
+
+func (c *showWorkersCmd) Run(ctx *context) error {
+	ret, err := ctx.LavaCon.WorkersShow(c.Hostname)
+	if err != nil {
+		return err
+	}
+
+	if c.Yaml {
+		d, err := yaml.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else if c.JSON {
+		d, err := json.Marshal(&ret)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(d))
+	} else {
+		fmt.Printf("hostname   : %s\n", ret.Hostname)
+		fmt.Printf("description: %s\n", ret.Description)
+		fmt.Printf("state      : %s\n", ret.State)
+		fmt.Printf("health     : %s\n", ret.Health)
+		fmt.Printf("devices    : %v\n", ret.Devices)
+		fmt.Printf("last ping  : %s\n", ret.LastPing)
+		fmt.Printf("job limit  : %d\n", ret.JobLimit)
+		fmt.Printf("version    : %s\n", ret.Version)
+		fmt.Printf("def. config: %v\n", ret.DefaultConfig)
+		fmt.Printf("def. env   : %v\n", ret.DefaultEnv)
+	}
+	return nil
+}
+
+type addWorkersCmd struct {
+	Hostname    string `arg:"" required:"" help:"The worker to add."`
+	Description string `flag:"" optional:"" help:"Description of the worker."`
+	Disabled    bool   `flag:"" optional:"" help:"Add the worker in maintenance." default:"false"`
+}
+
+func (c *addWorkersCmd) Run(ctx *context) error {
+	return ctx.LavaCon.WorkersAdd(c.Hostname, c.Description, c.Disabled)
+}
+
+type updateWorkersCmd struct {
+	Hostname    string `arg:"" required:"" help:"The worker to update."`
+	Description string `flag:"" optional:"" help:"Description of the worker. Default:Unchanged."`
+	Health      string `flag:"" optional:"" help:"[ACTIVE, MAINTENANCE, RETIRED]. Default:Unchanged."`
+}
+
+func (c *updateWorkersCmd) Run(ctx *context) error {
+	w, err := ctx.LavaCon.WorkersShow(c.Hostname)
+	if err != nil {
+		return err
+	}
+	if c.Description != "" {
+		w.Description = c.Description
+	}
+	if c.Health != "" {
+		w.Health = c.Health
+	}
+
+	return ctx.LavaCon.WorkersUpdate(w.Hostname, w.Description, w.Health)
+}
+
+type maintenanceWorkersCmd struct {
+	Hostname string `arg:"" required:"" help:"The worker to put into maintenance."`
+}
+
+func (c *maintenanceWorkersCmd) Run(ctx *context) error {
+	w, err := ctx.LavaCon.WorkersShow(c.Hostname)
+	if err != nil {
+		return err
+	}
+
+	return ctx.LavaCon.WorkersUpdate(w.Hostname, w.Description, "MAINTENANCE")
+}
+
+type activeWorkersCmd struct {
+	Hostname string `arg:"" required:"" help:"The worker to activate."`
+}
+
+func (c *activeWorkersCmd) Run(ctx *context) error {
+	w, err := ctx.LavaCon.WorkersShow(c.Hostname)
+	if err != nil {
+		return err
+	}
+
+	return ctx.LavaCon.WorkersUpdate(w.Hostname, w.Description, "ACTIVE")
+}
+
+type getWorkerConfigCmd struct {
+	Hostname string `arg:"" required:"" help:"Name of the worker."`
+}
+
+func (c *getWorkerConfigCmd) Run(ctx *context) error {
+
+	ret, err := ctx.LavaCon.WorkersConfigGet(c.Hostname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", ret)
+
+	return nil
+}
+
+type setWorkerConfigCmd struct {
+	Hostname string `arg:"" required:"" help:"Name of the worker."`
+	Filename string `arg:"" required:"" help:"Local filename of the dispatcher config."`
+}
+
+func (c *setWorkerConfigCmd) Run(ctx *context) error {
+	configFile, err := ioutil.ReadFile(c.Filename)
+	if err != nil && !strings.HasSuffix(c.Filename, ".yaml") {
+		configFile, err = ioutil.ReadFile(c.Filename + ".yaml")
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read file: #%v ", err)
+	}
+
+	return ctx.LavaCon.WorkersConfigSet(c.Hostname, string(configFile))
+}
+
+type getWorkerEnvCmd struct {
+	Hostname string `arg:"" required:"" help:"Name of the worker."`
+}
+
+func (c *getWorkerEnvCmd) Run(ctx *context) error {
+
+	ret, err := ctx.LavaCon.WorkersEnvGet(c.Hostname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", ret)
+
+	return nil
+}
+
+type setWorkerEnvCmd struct {
+	Hostname string `arg:"" required:"" help:"Name of the worker."`
+	Filename string `arg:"" required:"" help:"Local filename of the dispatcher env."`
+}
+
+func (c *setWorkerEnvCmd) Run(ctx *context) error {
+	envFile, err := ioutil.ReadFile(c.Filename)
+	if err != nil && !strings.HasSuffix(c.Filename, ".yaml") {
+		envFile, err = ioutil.ReadFile(c.Filename + ".yaml")
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read file: #%v ", err)
+	}
+
+	return ctx.LavaCon.WorkersEnvSet(c.Hostname, string(envFile))
+}
+
+type workerConfigCmd struct {
+	Get getWorkerConfigCmd `cmd:"" help:"Get (download) the dispatcher config from the server."`
+	Set setWorkerConfigCmd `cmd:"" help:"Set (upload) the dispatcher config to the server."`
+}
+
+type workerEnvCmd struct {
+	Get getWorkerEnvCmd `cmd:"" help:"Get (download) the dispatcher env from the server."`
+	Set setWorkerEnvCmd `cmd:"" help:"Set (upload) the dispatcher env to the server."`
+}
+
+type workersCmd struct {
+	List        listWorkersCmd        `cmd:"" help:"Lists workers"`
+	Show        showWorkersCmd        `cmd:"" help:"Show worker properties"`
+	Add         addWorkersCmd         `cmd:"" help:"Add a worker"`
+	Update      updateWorkersCmd      `cmd:"" help:"Update worker properties"`
+	Maintenance maintenanceWorkersCmd `cmd:"" help:"Put a worker into maintenance"`
+	Active      activeWorkersCmd      `cmd:"" help:"Put a worker back into service"`
+	Config      workerConfigCmd       `cmd:"" help:"Handle dispatcher config"`
+	Env         workerEnvCmd          `cmd:"" help:"Handle dispatcher env"`
+}
@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/alecthomas/kong v0.2.16
+	github.com/gorilla/websocket v1.2.0
 	github.com/kolo/xmlrpc v0.0.0-20201022064351-38db28db192b
 	gopkg.in/yaml.v2 v2.4.0
 )
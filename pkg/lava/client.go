@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import "context"
+
+// Client is implemented by Connection. Depend on Client instead of
+// *Connection to be able to substitute a fake in tests, like the one
+// provided by the lavafake package.
+type Client interface {
+	// devices
+	DevicesList() ([]DeviceList, error)
+	DevicesListContext(ctx context.Context) ([]DeviceList, error)
+	DevicesListFiltered(filter DevicesFilter) ([]DeviceList, error)
+	DevicesListFilteredContext(ctx context.Context, filter DevicesFilter) ([]DeviceList, error)
+	DevicesShow(hostname string) (*Device, error)
+	DevicesShowContext(ctx context.Context, hostname string) (*Device, error)
+	DevicesTagsList(hostname string) ([]string, error)
+	DevicesTagsListContext(ctx context.Context, hostname string) ([]string, error)
+	DevicesTagsDelete(hostname string, name string) error
+	DevicesTagsDeleteContext(ctx context.Context, hostname string, name string) error
+	DevicesTagsAdd(hostname string, name string) error
+	DevicesTagsAddContext(ctx context.Context, hostname string, name string) error
+	DevicesAdd(hostname string, s DeviceSettings) error
+	DevicesAddContext(ctx context.Context, hostname string, s DeviceSettings) error
+	DevicesUpdate(hostname string, s DeviceSettings) error
+	DevicesUpdateContext(ctx context.Context, hostname string, s DeviceSettings) error
+	DevicesDictionaryGet(hostname string, render bool) (string, error)
+	DevicesDictionaryGetContext(ctx context.Context, hostname string, render bool) (string, error)
+	DevicesDictionarySet(hostname string, dict string) error
+	DevicesDictionarySetContext(ctx context.Context, hostname string, dict string) error
+
+	// device types
+	DevicesTypesList(showAll bool) ([]DeviceTypesListing, error)
+	DevicesTypesListContext(ctx context.Context, showAll bool) ([]DeviceTypesListing, error)
+	DevicesTypesTemplateSet(name string, template string) error
+	DevicesTypesTemplateSetContext(ctx context.Context, name string, template string) error
+	DevicesTypesTemplateGet(name string) (string, error)
+	DevicesTypesTemplateGetContext(ctx context.Context, name string) (string, error)
+	DevicesTypesHealthCheckSet(name string, template string) error
+	DevicesTypesHealthCheckSetContext(ctx context.Context, name string, template string) error
+	DevicesTypesHealthCheckGet(name string) (string, error)
+	DevicesTypesHealthCheckGetContext(ctx context.Context, name string) (string, error)
+	DevicesTypesShow(name string) (*DeviceType, error)
+	DevicesTypesShowContext(ctx context.Context, name string) (*DeviceType, error)
+	DevicesTypesAdd(name string, s DeviceTypeSettings) error
+	DevicesTypesAddContext(ctx context.Context, name string, s DeviceTypeSettings) error
+	DevicesTypesUpdate(name string, s DeviceTypeSettings) error
+	DevicesTypesUpdateContext(ctx context.Context, name string, s DeviceTypeSettings) error
+	DevicesTypesAliasesList(name string) ([]string, error)
+	DevicesTypesAliasesListContext(ctx context.Context, name string) ([]string, error)
+	DevicesTypesAliasesAdd(name string, alias string) error
+	DevicesTypesAliasesAddContext(ctx context.Context, name string, alias string) error
+	DevicesTypesAliasesDelete(name string, alias string) error
+	DevicesTypesAliasesDeleteContext(ctx context.Context, name string, alias string) error
+
+	// jobs
+	JobsList(state string, health string, start int, limit int) ([]JobsListing, error)
+	JobsListContext(ctx context.Context, state string, health string, start int, limit int) ([]JobsListing, error)
+	JobsListFiltered(filter JobsFilter, start int, limit int) ([]JobsListing, error)
+	JobsListFilteredContext(ctx context.Context, filter JobsFilter, start int, limit int) ([]JobsListing, error)
+	JobsIter(state string, health string, pageSize int) *JobsIterator
+	JobsIterContext(ctx context.Context, state string, health string, pageSize int) *JobsIterator
+	JobsIterFrom(state string, health string, start int, pageSize int) *JobsIterator
+	JobsIterFromContext(ctx context.Context, state string, health string, start int, pageSize int) *JobsIterator
+	JobsQueue(deviceTypes []string, start int, limit int) ([]JobsQueueListing, error)
+	JobsQueueContext(ctx context.Context, deviceTypes []string, start int, limit int) ([]JobsQueueListing, error)
+	JobsShow(id int) (*JobState, error)
+	JobsShowContext(ctx context.Context, id int) (*JobState, error)
+	JobsDefinition(id int) (JobDefintion, error)
+	JobsDefinitionContext(ctx context.Context, id int) (JobDefintion, error)
+	JobsValidate(def string, strict bool) (JobErrors, error)
+	JobsValidateContext(ctx context.Context, def string, strict bool) (JobErrors, error)
+	JobsSubmitString(def string) ([]int, error)
+	JobsSubmitStringContext(ctx context.Context, def string) ([]int, error)
+	JobsSubmit(def *JobStruct) ([]int, error)
+	JobsSubmitContext(ctx context.Context, def *JobStruct) ([]int, error)
+	JobsConfiguration(id int) (*JobConfiguration, error)
+	JobsConfigurationContext(ctx context.Context, id int) (*JobConfiguration, error)
+	JobsResubmit(id int) ([]int, error)
+	JobsResubmitContext(ctx context.Context, id int) ([]int, error)
+	JobsResolve(number string) (int, error)
+	JobsResolveContext(ctx context.Context, number string) (int, error)
+	JobsCancel(id int) error
+	JobsCancelContext(ctx context.Context, id int) error
+	JobsFail(id int) error
+	JobsFailContext(ctx context.Context, id int) error
+	JobsLogs(id int, raw bool) (*JobsLogs, error)
+	JobsLogsContext(ctx context.Context, id int, raw bool) (*JobsLogs, error)
+	JobsLogsRange(id int, start int, end int, raw bool) (*JobsLogs, error)
+	JobsLogsRangeContext(ctx context.Context, id int, start int, end int, raw bool) (*JobsLogs, error)
+
+	// results
+	ResultsAsYAML(id int) (string, error)
+	ResultsAsYAMLContext(ctx context.Context, id int) (string, error)
+	Results(id int) (Result, error)
+	ResultsContext(ctx context.Context, id int) (Result, error)
+	ResultsAsJSON(id int) (string, error)
+	ResultsAsJSONContext(ctx context.Context, id int) (string, error)
+	ResultsAsCSV(id int) (string, error)
+	ResultsAsCSVContext(ctx context.Context, id int) (string, error)
+	ResultsSuiteAsYAML(id int, suite string) (string, error)
+	ResultsSuiteAsYAMLContext(ctx context.Context, id int, suite string) (string, error)
+	ResultsSuiteAsCSV(id int, suite string) (string, error)
+	ResultsSuiteAsCSVContext(ctx context.Context, id int, suite string) (string, error)
+	ResultsSuite(id int, suite string) (Result, error)
+	ResultsSuiteContext(ctx context.Context, id int, suite string) (Result, error)
+	ResultsSuiteAsJSON(id int, suite string) (string, error)
+	ResultsSuiteAsJSONContext(ctx context.Context, id int, suite string) (string, error)
+	ResultsCaseAsYAML(id int, suite string, testCase string) (string, error)
+	ResultsCaseAsYAMLContext(ctx context.Context, id int, suite string, testCase string) (string, error)
+	ResultsCaseAsCSV(id int, suite string, testCase string) (string, error)
+	ResultsCaseAsCSVContext(ctx context.Context, id int, suite string, testCase string) (string, error)
+	ResultsCase(id int, suite string, testCase string) (Result, error)
+	ResultsCaseContext(ctx context.Context, id int, suite string, testCase string) (Result, error)
+	ResultsCaseAsJSON(id int, suite string, testCase string) (string, error)
+	ResultsCaseAsJSONContext(ctx context.Context, id int, suite string, testCase string) (string, error)
+
+	// workers
+	WorkersList() ([]Worker, error)
+	WorkersListContext(ctx context.Context) ([]Worker, error)
+	WorkersShow(hostname string) (*WorkerDetail, error)
+	WorkersShowContext(ctx context.Context, hostname string) (*WorkerDetail, error)
+	WorkersAdd(hostname string, description string, disabled bool) error
+	WorkersAddContext(ctx context.Context, hostname string, description string, disabled bool) error
+	WorkersUpdate(hostname string, description string, health string) error
+	WorkersUpdateContext(ctx context.Context, hostname string, description string, health string) error
+	WorkersConfigGet(hostname string) (string, error)
+	WorkersConfigGetContext(ctx context.Context, hostname string) (string, error)
+	WorkersConfigSet(hostname string, config string) error
+	WorkersConfigSetContext(ctx context.Context, hostname string, config string) error
+	WorkersEnvGet(hostname string) (string, error)
+	WorkersEnvGetContext(ctx context.Context, hostname string) (string, error)
+	WorkersEnvSet(hostname string, env string) error
+	WorkersEnvSetContext(ctx context.Context, hostname string, env string) error
+
+	// tags
+	TagsList() ([]Tag, error)
+	TagsListContext(ctx context.Context) ([]Tag, error)
+	TagsShow(name string) (*TagDetail, error)
+	TagsShowContext(ctx context.Context, name string) (*TagDetail, error)
+	TagsAdd(name string, description string) error
+	TagsAddContext(ctx context.Context, name string, description string) error
+	TagsDelete(name string) error
+	TagsDeleteContext(ctx context.Context, name string) error
+
+	// users
+	UsersList() ([]User, error)
+	UsersListContext(ctx context.Context) ([]User, error)
+	UsersShow(username string) (*UserDetail, error)
+	UsersShowContext(ctx context.Context, username string) (*UserDetail, error)
+	UsersAdd(username string, s UserSettings) error
+	UsersAddContext(ctx context.Context, username string, s UserSettings) error
+	UsersUpdate(username string, s UserSettings) error
+	UsersUpdateContext(ctx context.Context, username string, s UserSettings) error
+	UsersDelete(username string) error
+	UsersDeleteContext(ctx context.Context, username string) error
+
+	// groups
+	GroupsList() ([]Group, error)
+	GroupsListContext(ctx context.Context) ([]Group, error)
+	GroupsShow(name string) (*GroupDetail, error)
+	GroupsShowContext(ctx context.Context, name string) (*GroupDetail, error)
+	GroupsPermsList(name string) ([]Permission, error)
+	GroupsPermsListContext(ctx context.Context, name string) ([]Permission, error)
+	GroupsPermsAdd(name string, app string, codename string) error
+	GroupsPermsAddContext(ctx context.Context, name string, app string, codename string) error
+	GroupsPermsDelete(name string, app string, codename string) error
+	GroupsPermsDeleteContext(ctx context.Context, name string, app string, codename string) error
+
+	// system
+	SystemVersion() (string, error)
+	SystemVersionContext(ctx context.Context) (string, error)
+	SystemAPIVersion() (int, error)
+	SystemAPIVersionContext(ctx context.Context) (int, error)
+	SystemWhoami() (string, error)
+	SystemWhoamiContext(ctx context.Context) (string, error)
+	SystemListMethods() ([]string, error)
+	SystemListMethodsContext(ctx context.Context) ([]string, error)
+	SystemMethodHelp(name string) (string, error)
+	SystemMethodHelpContext(ctx context.Context, name string) (string, error)
+	SystemMethodSignature(name string) ([][]string, error)
+	SystemMethodSignatureContext(ctx context.Context, name string) ([][]string, error)
+
+	// events
+	EventsURI() (string, error)
+	EventsListen() (*EventListener, error)
+	EventsListenContext(ctx context.Context) (*EventListener, error)
+	EventsListenURI(uri string) (*EventListener, error)
+	EventsListenURIContext(ctx context.Context, uri string) (*EventListener, error)
+}
+
+var _ Client = Connection{}
@@ -17,6 +17,19 @@ type ConfigIndentity struct {
 	URI      string `yaml:"uri,omitempty"`
 	Username string `yaml:"username,omitempty"`
 	Proxy    string `yaml:"proxy,omitempty"`
+	// CACert is a PEM file holding additional CAs to trust
+	CACert string `yaml:"ca_cert,omitempty"`
+	// ClientCert and ClientKey are PEM files used for client certificate authentication
+	ClientCert string `yaml:"client_cert,omitempty"`
+	ClientKey  string `yaml:"client_key,omitempty"`
+	// Insecure disables verification of the server certificate
+	Insecure bool `yaml:"insecure,omitempty"`
+	// Timeout of a single request in seconds
+	Timeout int `yaml:"timeout,omitempty"`
+	// Auth selects how the token is sent: basic (default), token or bearer
+	Auth string `yaml:"auth,omitempty"`
+	// API selects the API used by the server: xmlrpc (default) or rest
+	API string `yaml:"api,omitempty"`
 }
 
 // GetConf loads the lavacli.yaml
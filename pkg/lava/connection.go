@@ -3,9 +3,17 @@
 package lava
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"time"
 
 	"github.com/kolo/xmlrpc"
 )
@@ -13,6 +21,25 @@ import (
 // ConnectionOptions allows to pass additional parameters
 type ConnectionOptions struct {
 	Transport *http.Transport
+	// CACert is a PEM file holding additional CAs to trust
+	CACert string
+	// ClientCert and ClientKey are PEM files used for client certificate authentication
+	ClientCert string
+	ClientKey  string
+	// Insecure disables verification of the server certificate
+	Insecure bool
+	// Timeout limits the duration of a single request. Zero means no timeout.
+	Timeout time.Duration
+	// Username and Token are sent in the Authorization header, never in the URI.
+	// Credentials found in the URI are moved to the header as well.
+	Username string
+	Token    string
+	// Auth selects how the token is sent: AuthBasic (default), AuthToken or AuthBearer
+	Auth string
+	// Debug receives a log of all requests if set. The token is masked.
+	Debug io.Writer
+	// API selects the API used by the server: APIXMLRPC (default) or APIREST
+	API string
 }
 
 // DefaultOptions must be passed as argument to the Connect.. methods if no overwrites are made
@@ -20,30 +47,253 @@ var DefaultOptions = ConnectionOptions{
 	Transport: &http.Transport{},
 }
 
+// none is replaced by an XMLRPC nil value before the request is sent,
+// as github.com/kolo/xmlrpc is unable to encode nil arguments.
+const none = "__lavacli_none__"
+
+// optional returns none for empty strings, which tells the server to use its default
+func optional(s string) interface{} {
+	if s == "" {
+		return none
+	}
+	return s
+}
+
+// optionalBool returns none for nil pointers, which tells the server to use its default
+func optionalBool(b *bool) interface{} {
+	if b == nil {
+		return none
+	}
+	return *b
+}
+
+// optionalInt returns none for nil pointers, which tells the server to use its default
+func optionalInt(i *int) interface{} {
+	if i == nil {
+		return none
+	}
+	return *i
+}
+
+// trimNone drops trailing none arguments, allowing to talk to servers
+// that do not know about recently added optional arguments
+func trimNone(args []interface{}) []interface{} {
+	for len(args) > 0 && args[len(args)-1] == none {
+		args = args[:len(args)-1]
+	}
+	return args
+}
+
+// noneTransport replaces none arguments by XMLRPC nil values
+type noneTransport struct {
+	base http.RoundTripper
+}
+
+func (t noneTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.ReplaceAll(body,
+		[]byte("<value><string>"+none+"</string></value>"),
+		[]byte("<value><nil/></value>"))
+
+	r := req.Clone(req.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", fmt.Sprintf("%d", len(body)))
+
+	return t.base.RoundTrip(r)
+}
+
 // Connection holds metadata used to communicate with the LAVA XMLRPC server
 type Connection struct {
-	con   *xmlrpc.Client
+	con   *http.Client
 	proxy string
 	uri   string
-	opt   ConnectionOptions
+	// rest is the base URI of the REST API, empty if the XMLRPC API is used
+	rest string
+	opt  ConnectionOptions
+}
+
+// call issues an XMLRPC request and decodes the response into reply.
+// Faults are returned as NotFoundError, PermissionDeniedError,
+// InvalidRequestError or ServerError, network errors as TransportError.
+// Following github.com/kolo/xmlrpc, args of type []interface{} are passed as
+// multiple parameters and nil args as no parameter at all.
+// The request is aborted as soon as ctx is done.
+func (c Connection) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	var params []interface{}
+	if p, ok := args.([]interface{}); ok {
+		params = p
+	} else if args != nil {
+		params = []interface{}{args}
+	}
+
+	body, err := xmlrpc.EncodeMethodCall(method, params...)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.uri, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+
+	resp, err := c.con.Do(req)
+	if err != nil {
+		return &TransportError{Err: redactError(err, c.opt.Token)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return httpError(resp.StatusCode, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &TransportError{Err: redactError(err, c.opt.Token)}
+	}
+
+	r := xmlrpc.Response(data)
+	err = r.Err()
+	if err != nil {
+		return faultError(err)
+	}
+	if reply == nil {
+		return nil
+	}
+
+	return r.Unmarshal(reply)
+}
+
+// transport returns a copy of the transport configured according to the options
+func (opt ConnectionOptions) transport(proxy string) (*http.Transport, error) {
+	var t *http.Transport
+	if opt.Transport != nil {
+		t = opt.Transport.Clone()
+	} else {
+		t = &http.Transport{}
+	}
+
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+
+	if opt.CACert == "" && opt.ClientCert == "" && opt.ClientKey == "" && !opt.Insecure {
+		return t, nil
+	}
+
+	cfg := &tls.Config{}
+	if t.TLSClientConfig != nil {
+		cfg = t.TLSClientConfig.Clone()
+	}
+	cfg.InsecureSkipVerify = cfg.InsecureSkipVerify || opt.Insecure
+
+	if opt.CACert != "" {
+		pem, err := ioutil.ReadFile(opt.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA certificate: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No PEM encoded certificate found in CA certificate %s", opt.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if opt.ClientCert != "" || opt.ClientKey != "" {
+		if opt.ClientCert == "" || opt.ClientKey == "" {
+			return nil, fmt.Errorf("Client certificate and client key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(opt.ClientCert, opt.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load client certificate %s with key %s: %v",
+				opt.ClientCert, opt.ClientKey, err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	t.TLSClientConfig = cfg
+
+	return t, nil
 }
 
 // ConnectByURI connects to an LAVA XMLRPC server using the provided URI, proxy and transport
 func ConnectByURI(uri string, proxy string, opt ConnectionOptions) (*Connection, error) {
 	var ret Connection
-	if proxy != "" {
-		u, err := url.Parse(proxy)
+	uri, username, token, err := splitCredentials(uri)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Token == "" {
+		opt.Username = username
+		opt.Token = token
+	}
+	// All requests not supported by the REST API use XMLRPC
+	uri, err = xmlrpcURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opt.Auth {
+	case "":
+		opt.Auth = AuthBasic
+	case AuthBasic, AuthToken, AuthBearer:
+	default:
+		return nil, fmt.Errorf("Unknown authentication scheme %s", opt.Auth)
+	}
+
+	switch opt.API {
+	case "":
+		opt.API = APIXMLRPC
+	case APIXMLRPC:
+	case APIREST:
+		ret.rest, err = restURI(uri)
 		if err != nil {
 			return nil, err
 		}
-		opt.Transport.Proxy = http.ProxyURL(u)
+		// The REST API only needs the token
+		if opt.Username == "" && opt.Auth == AuthBasic {
+			opt.Auth = AuthToken
+		}
+	default:
+		return nil, fmt.Errorf("Unknown API %s", opt.API)
+	}
+	if opt.Auth == AuthBasic && opt.Token != "" && opt.Username == "" {
+		return nil, fmt.Errorf("Basic authentication requires a username")
 	}
 
-	client, err := xmlrpc.NewClient(uri, opt.Transport)
+	opt.Transport, err = opt.transport(proxy)
+	if err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
 	}
-	ret.con = client
+
+	var rt http.RoundTripper = opt.Transport
+	if opt.Debug != nil {
+		rt = debugTransport{base: rt, w: opt.Debug}
+	}
+	rt = authTransport{base: rt, scheme: opt.Auth, username: opt.Username, token: opt.Token}
+	ret.con = &http.Client{
+		Transport: noneTransport{base: rt},
+		Jar:       jar,
+		Timeout:   opt.Timeout,
+	}
 	ret.proxy = proxy
 	ret.uri = uri
 	ret.opt = opt
@@ -53,7 +303,6 @@ func ConnectByURI(uri string, proxy string, opt ConnectionOptions) (*Connection,
 
 // ConnectByConfigID connects to an LAVA XMLRPC server using the provided identity and lavacli.yaml
 func ConnectByConfigID(identityName string, opt ConnectionOptions) (*Connection, error) {
-	var u string
 	var c ConfigIndentity
 
 	configs, err := GetConf()
@@ -79,36 +328,45 @@ func ConnectByConfigID(identityName string, opt ConnectionOptions) (*Connection,
 		return nil, fmt.Errorf("No URI found in config")
 	}
 
-	if c.Username != "" && c.Token != "" {
-		url, err := url.Parse(c.URI)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to parse URI: %v", err)
-		}
-		u = fmt.Sprintf("%s://%s:%s@%s%s", url.Scheme, c.Username, c.Token, url.Host, url.Path)
-	} else if c.URI != "" {
-		u = c.URI
+	// Options passed by the caller take precedence over the identity
+	if opt.CACert == "" {
+		opt.CACert = c.CACert
+	}
+	if opt.ClientCert == "" && opt.ClientKey == "" {
+		opt.ClientCert = c.ClientCert
+		opt.ClientKey = c.ClientKey
+	}
+	opt.Insecure = opt.Insecure || c.Insecure
+	if opt.Timeout == 0 && c.Timeout > 0 {
+		opt.Timeout = time.Duration(c.Timeout) * time.Second
+	}
+
+	if opt.Auth == "" {
+		opt.Auth = c.Auth
+	}
+	if opt.API == "" {
+		opt.API = c.API
+	}
+	// Basic authentication requires a username, the other schemes and the
+	// REST API only the token
+	if c.Token != "" && (c.Username != "" || (opt.Auth != "" && opt.Auth != AuthBasic) || opt.API == APIREST) {
+		opt.Username = c.Username
+		opt.Token = c.Token
 	}
 
-	return ConnectByURI(u, c.Proxy, opt)
+	return ConnectByURI(c.URI, c.Proxy, opt)
 }
 
 // ConnectByCredentials connects to an LAVA XMLRPC server using the provided credentials
 func ConnectByCredentials(uri string, username string, token string, proxy string, opt ConnectionOptions) (*Connection, error) {
-	var u string
-
 	if uri == "" {
 		return nil, fmt.Errorf("No URI specified")
 	}
 
 	if username != "" && token != "" {
-		url, err := url.Parse(uri)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to parse URI: %v", err)
-		}
-		u = fmt.Sprintf("%s://%s:%s@%s%s", url.Scheme, username, token, url.Host, url.Path)
-	} else if uri != "" {
-		u = uri
+		opt.Username = username
+		opt.Token = token
 	}
 
-	return ConnectByURI(u, proxy, opt)
+	return ConnectByURI(uri, proxy, opt)
 }
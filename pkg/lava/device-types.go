@@ -2,7 +2,11 @@
 
 package lava
 
-import "encoding/base64"
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+)
 
 type DeviceTypesListing struct {
 	Devices   int    `xmlrpc:"devices" json:"devices" yaml:"devices"`
@@ -11,10 +15,43 @@ type DeviceTypesListing struct {
 	Template  bool   `xmlrpc:"template" json:"template" yaml:"template"`
 }
 
+// DeviceType represents data as returned by LAVA XMLRPC scheduler.device_types.show
+type DeviceType struct {
+	Name              string   `xmlrpc:"name" json:"name" yaml:"name"`
+	Description       string   `xmlrpc:"description" json:"description" yaml:"description"`
+	Display           bool     `xmlrpc:"display" json:"display" yaml:"display"`
+	OwnersOnly        bool     `xmlrpc:"owners_only" json:"owners_only" yaml:"owners_only"`
+	HealthDisabled    bool     `xmlrpc:"health_disabled" json:"health_disabled" yaml:"health_disabled"`
+	HealthFrequency   int      `xmlrpc:"health_frequency" json:"health_frequency" yaml:"health_frequency"`
+	HealthDenominator string   `xmlrpc:"health_denominator" json:"health_denominator" yaml:"health_denominator"`
+	Aliases           []string `xmlrpc:"aliases" json:"aliases" yaml:"aliases"`
+	Devices           []string `xmlrpc:"devices" json:"devices" yaml:"devices"`
+	DefaultTemplate   bool     `xmlrpc:"default_template" json:"default_template" yaml:"default_template"`
+}
+
+// DeviceTypeSettings holds the device-type properties passed to LAVA XMLRPC
+// scheduler.device_types.add and scheduler.device_types.update.
+// Nil fields are left unchanged on update.
+type DeviceTypeSettings struct {
+	Description string
+	Display     *bool
+	OwnersOnly  *bool
+	// HealthFrequency is the number of HealthDenominator between two health checks
+	HealthFrequency *int
+	// HealthDenominator is either "hours" or "jobs"
+	HealthDenominator string
+	HealthDisabled    *bool
+}
+
 func (c Connection) DevicesTypesList(showAll bool) ([]DeviceTypesListing, error) {
+	return c.DevicesTypesListContext(context.Background(), showAll)
+}
+
+// DevicesTypesListContext is like DevicesTypesList but uses ctx for the request
+func (c Connection) DevicesTypesListContext(ctx context.Context, showAll bool) ([]DeviceTypesListing, error) {
 	var ret []DeviceTypesListing
 
-	err := c.con.Call("scheduler.device_types.list", showAll, &ret)
+	err := c.call(ctx, "scheduler.device_types.list", showAll, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -23,12 +60,17 @@ func (c Connection) DevicesTypesList(showAll bool) ([]DeviceTypesListing, error)
 }
 
 func (c Connection) DevicesTypesTemplateSet(name string, template string) error {
+	return c.DevicesTypesTemplateSetContext(context.Background(), name, template)
+}
+
+// DevicesTypesTemplateSetContext is like DevicesTypesTemplateSet but uses ctx for the request
+func (c Connection) DevicesTypesTemplateSetContext(ctx context.Context, name string, template string) error {
 	var ret []DeviceTypesListing
 	var args []interface{}
 	args = append(args, name)
 	args = append(args, template)
 
-	err := c.con.Call("scheduler.device_types.set_template", args, &ret)
+	err := c.call(ctx, "scheduler.device_types.set_template", args, &ret)
 	if err != nil {
 		return err
 	}
@@ -37,9 +79,14 @@ func (c Connection) DevicesTypesTemplateSet(name string, template string) error
 }
 
 func (c Connection) DevicesTypesTemplateGet(name string) (string, error) {
+	return c.DevicesTypesTemplateGetContext(context.Background(), name)
+}
+
+// DevicesTypesTemplateGetContext is like DevicesTypesTemplateGet but uses ctx for the request
+func (c Connection) DevicesTypesTemplateGetContext(ctx context.Context, name string) (string, error) {
 	var data string
 
-	err := c.con.Call("scheduler.device_types.get_template", name, &data)
+	err := c.call(ctx, "scheduler.device_types.get_template", name, &data)
 	if err != nil {
 		return "", err
 	}
@@ -53,12 +100,17 @@ func (c Connection) DevicesTypesTemplateGet(name string) (string, error) {
 }
 
 func (c Connection) DevicesTypesHealthCheckSet(name string, template string) error {
+	return c.DevicesTypesHealthCheckSetContext(context.Background(), name, template)
+}
+
+// DevicesTypesHealthCheckSetContext is like DevicesTypesHealthCheckSet but uses ctx for the request
+func (c Connection) DevicesTypesHealthCheckSetContext(ctx context.Context, name string, template string) error {
 	var ret []DeviceTypesListing
 	var args []interface{}
 	args = append(args, name)
 	args = append(args, template)
 
-	err := c.con.Call("scheduler.device_types.set_health_check", args, &ret)
+	err := c.call(ctx, "scheduler.device_types.set_health_check", args, &ret)
 	if err != nil {
 		return err
 	}
@@ -67,9 +119,14 @@ func (c Connection) DevicesTypesHealthCheckSet(name string, template string) err
 }
 
 func (c Connection) DevicesTypesHealthCheckGet(name string) (string, error) {
+	return c.DevicesTypesHealthCheckGetContext(context.Background(), name)
+}
+
+// DevicesTypesHealthCheckGetContext is like DevicesTypesHealthCheckGet but uses ctx for the request
+func (c Connection) DevicesTypesHealthCheckGetContext(ctx context.Context, name string) (string, error) {
 	var data string
 
-	err := c.con.Call("scheduler.device_types.get_health_check", name, &data)
+	err := c.call(ctx, "scheduler.device_types.get_health_check", name, &data)
 	if err != nil {
 		return "", err
 	}
@@ -81,3 +138,123 @@ func (c Connection) DevicesTypesHealthCheckGet(name string) (string, error) {
 
 	return string(decoded), nil
 }
+
+func (c Connection) DevicesTypesShow(name string) (*DeviceType, error) {
+	return c.DevicesTypesShowContext(context.Background(), name)
+}
+
+// DevicesTypesShowContext is like DevicesTypesShow but uses ctx for the request
+func (c Connection) DevicesTypesShowContext(ctx context.Context, name string) (*DeviceType, error) {
+	var ret DeviceType
+
+	err := c.call(ctx, "scheduler.device_types.show", name, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// DevicesTypesAdd adds a new device-type. Unset fields use the defaults of
+// the LAVA web interface: displayed, not owners only, a health check every 24 hours.
+func (c Connection) DevicesTypesAdd(name string, s DeviceTypeSettings) error {
+	return c.DevicesTypesAddContext(context.Background(), name, s)
+}
+
+// DevicesTypesAddContext is like DevicesTypesAdd but uses ctx for the request
+func (c Connection) DevicesTypesAddContext(ctx context.Context, name string, s DeviceTypeSettings) error {
+	display := true
+	if s.Display != nil {
+		display = *s.Display
+	}
+	ownersOnly := false
+	if s.OwnersOnly != nil {
+		ownersOnly = *s.OwnersOnly
+	}
+	frequency := 24
+	if s.HealthFrequency != nil {
+		frequency = *s.HealthFrequency
+	}
+	denominator := "hours"
+	if s.HealthDenominator != "" {
+		denominator = s.HealthDenominator
+	}
+	if denominator != "hours" && denominator != "jobs" {
+		return fmt.Errorf("Invalid health denominator %s", denominator)
+	}
+
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, s.Description)
+	args = append(args, display)
+	args = append(args, ownersOnly)
+	args = append(args, frequency)
+	args = append(args, denominator)
+
+	return c.call(ctx, "scheduler.device_types.add", args, nil)
+}
+
+// DevicesTypesUpdate updates the properties of an existing device-type
+func (c Connection) DevicesTypesUpdate(name string, s DeviceTypeSettings) error {
+	return c.DevicesTypesUpdateContext(context.Background(), name, s)
+}
+
+// DevicesTypesUpdateContext is like DevicesTypesUpdate but uses ctx for the request
+func (c Connection) DevicesTypesUpdateContext(ctx context.Context, name string, s DeviceTypeSettings) error {
+	if s.HealthDenominator != "" && s.HealthDenominator != "hours" && s.HealthDenominator != "jobs" {
+		return fmt.Errorf("Invalid health denominator %s", s.HealthDenominator)
+	}
+
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, optional(s.Description))
+	args = append(args, optionalBool(s.Display))
+	args = append(args, optionalBool(s.OwnersOnly))
+	args = append(args, optionalInt(s.HealthFrequency))
+	args = append(args, optional(s.HealthDenominator))
+	args = append(args, optionalBool(s.HealthDisabled))
+
+	return c.call(ctx, "scheduler.device_types.update", trimNone(args), nil)
+}
+
+func (c Connection) DevicesTypesAliasesList(name string) ([]string, error) {
+	return c.DevicesTypesAliasesListContext(context.Background(), name)
+}
+
+// DevicesTypesAliasesListContext is like DevicesTypesAliasesList but uses ctx for the request
+func (c Connection) DevicesTypesAliasesListContext(ctx context.Context, name string) ([]string, error) {
+	var ret []string
+
+	err := c.call(ctx, "scheduler.device_types.aliases.list", name, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c Connection) DevicesTypesAliasesAdd(name string, alias string) error {
+	return c.DevicesTypesAliasesAddContext(context.Background(), name, alias)
+}
+
+// DevicesTypesAliasesAddContext is like DevicesTypesAliasesAdd but uses ctx for the request
+func (c Connection) DevicesTypesAliasesAddContext(ctx context.Context, name string, alias string) error {
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, alias)
+
+	return c.call(ctx, "scheduler.device_types.aliases.add", args, nil)
+}
+
+func (c Connection) DevicesTypesAliasesDelete(name string, alias string) error {
+	return c.DevicesTypesAliasesDeleteContext(context.Background(), name, alias)
+}
+
+// DevicesTypesAliasesDeleteContext is like DevicesTypesAliasesDelete but uses ctx for the request
+func (c Connection) DevicesTypesAliasesDeleteContext(ctx context.Context, name string, alias string) error {
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, alias)
+
+	return c.call(ctx, "scheduler.device_types.aliases.delete", args, nil)
+}
@@ -2,6 +2,13 @@
 
 package lava
 
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
 type DeviceList struct {
 	Hostname   string `xmlrpc:"hostname"`
 	Type       string `xmlrpc:"type"`
@@ -12,13 +19,71 @@ type DeviceList struct {
 }
 
 func (c Connection) DevicesList() ([]DeviceList, error) {
+	return c.DevicesListContext(context.Background())
+}
+
+// DevicesListContext is like DevicesList but uses ctx for the request
+func (c Connection) DevicesListContext(ctx context.Context) ([]DeviceList, error) {
+	if c.rest != "" {
+		return c.restDevicesList(ctx, DevicesFilter{})
+	}
 	var ret []DeviceList
 
-	err := c.con.Call("scheduler.devices.list", nil, &ret)
+	err := c.call(ctx, "scheduler.devices.list", nil, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// DevicesFilter selects devices by DevicesListFiltered. Empty fields match all devices.
+type DevicesFilter struct {
+	DeviceType string
+	// State is one of IDLE, RESERVED, RUNNING
+	State string
+	// Health is one of GOOD, UNKNOWN, LOOPING, BAD, MAINTENANCE, RETIRED
+	Health string
+}
+
+// Match returns true if the device matches all criteria of the filter
+func (f DevicesFilter) Match(d DeviceList) bool {
+	if f.DeviceType != "" && f.DeviceType != d.Type {
+		return false
+	}
+	if f.State != "" && !strings.EqualFold(f.State, d.State) {
+		return false
+	}
+	if f.Health != "" && !strings.EqualFold(f.Health, d.Health) {
+		return false
+	}
+
+	return true
+}
+
+// DevicesListFiltered returns the devices matching the filter. The REST API
+// filters on the server, the XMLRPC API lists all devices and filters locally.
+func (c Connection) DevicesListFiltered(filter DevicesFilter) ([]DeviceList, error) {
+	return c.DevicesListFilteredContext(context.Background(), filter)
+}
+
+// DevicesListFilteredContext is like DevicesListFiltered but uses ctx for the requests
+func (c Connection) DevicesListFilteredContext(ctx context.Context, filter DevicesFilter) ([]DeviceList, error) {
+	if c.rest != "" {
+		return c.restDevicesList(ctx, filter)
+	}
+	list, err := c.DevicesListContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	ret := []DeviceList{}
+	for _, d := range list {
+		if filter.Match(d) {
+			ret = append(ret, d)
+		}
+	}
+
 	return ret, nil
 }
 
@@ -37,9 +102,14 @@ type Device struct {
 }
 
 func (c Connection) DevicesShow(hostname string) (*Device, error) {
+	return c.DevicesShowContext(context.Background(), hostname)
+}
+
+// DevicesShowContext is like DevicesShow but uses ctx for the request
+func (c Connection) DevicesShowContext(ctx context.Context, hostname string) (*Device, error) {
 	var ret Device
 
-	err := c.con.Call("scheduler.devices.show", hostname, &ret)
+	err := c.call(ctx, "scheduler.devices.show", hostname, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +118,14 @@ func (c Connection) DevicesShow(hostname string) (*Device, error) {
 }
 
 func (c Connection) DevicesTagsList(hostname string) ([]string, error) {
+	return c.DevicesTagsListContext(context.Background(), hostname)
+}
+
+// DevicesTagsListContext is like DevicesTagsList but uses ctx for the request
+func (c Connection) DevicesTagsListContext(ctx context.Context, hostname string) ([]string, error) {
 	var ret []string
 
-	err := c.con.Call("scheduler.devices.tags.list", hostname, &ret)
+	err := c.call(ctx, "scheduler.devices.tags.list", hostname, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -59,17 +134,138 @@ func (c Connection) DevicesTagsList(hostname string) ([]string, error) {
 }
 
 func (c Connection) DevicesTagsDelete(hostname string, name string) error {
+	return c.DevicesTagsDeleteContext(context.Background(), hostname, name)
+}
+
+// DevicesTagsDeleteContext is like DevicesTagsDelete but uses ctx for the request
+func (c Connection) DevicesTagsDeleteContext(ctx context.Context, hostname string, name string) error {
 	var args []interface{}
 	args = append(args, hostname)
 	args = append(args, name)
 
-	return c.con.Call("scheduler.devices.tags.delete", args, nil)
+	return c.call(ctx, "scheduler.devices.tags.delete", args, nil)
 }
 
 func (c Connection) DevicesTagsAdd(hostname string, name string) error {
+	return c.DevicesTagsAddContext(context.Background(), hostname, name)
+}
+
+// DevicesTagsAddContext is like DevicesTagsAdd but uses ctx for the request
+func (c Connection) DevicesTagsAddContext(ctx context.Context, hostname string, name string) error {
 	var args []interface{}
 	args = append(args, hostname)
 	args = append(args, name)
 
-	return c.con.Call("scheduler.devices.tags.add", args, nil)
+	return c.call(ctx, "scheduler.devices.tags.add", args, nil)
+}
+
+// DeviceSettings holds the device properties passed to LAVA XMLRPC
+// scheduler.devices.add and scheduler.devices.update.
+// Empty fields are left to the server, meaning unchanged on update.
+type DeviceSettings struct {
+	DeviceType    string
+	Worker        string
+	Description   string
+	Health        string
+	PhysicalOwner string
+	PhysicalGroup string
+	Public        *bool
+}
+
+// DevicesAdd adds a new device. DeviceType and Worker are mandatory.
+// The device is public unless specified otherwise.
+func (c Connection) DevicesAdd(hostname string, s DeviceSettings) error {
+	return c.DevicesAddContext(context.Background(), hostname, s)
+}
+
+// DevicesAddContext is like DevicesAdd but uses ctx for the request
+func (c Connection) DevicesAddContext(ctx context.Context, hostname string, s DeviceSettings) error {
+	if s.DeviceType == "" || s.Worker == "" {
+		return fmt.Errorf("Must specify device type and worker")
+	}
+	public := true
+	if s.Public != nil {
+		public = *s.Public
+	}
+
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, s.DeviceType)
+	args = append(args, s.Worker)
+	args = append(args, optional(s.PhysicalOwner))
+	args = append(args, optional(s.PhysicalGroup))
+	args = append(args, public)
+	args = append(args, optional(s.Health))
+	args = append(args, optional(s.Description))
+
+	return c.call(ctx, "scheduler.devices.add", trimNone(args), nil)
+}
+
+// DevicesUpdate updates the properties of an existing device
+func (c Connection) DevicesUpdate(hostname string, s DeviceSettings) error {
+	return c.DevicesUpdateContext(context.Background(), hostname, s)
+}
+
+// DevicesUpdateContext is like DevicesUpdate but uses ctx for the request
+func (c Connection) DevicesUpdateContext(ctx context.Context, hostname string, s DeviceSettings) error {
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, optional(s.Worker))
+	args = append(args, optional(s.PhysicalOwner))
+	args = append(args, optional(s.PhysicalGroup))
+	args = append(args, optionalBool(s.Public))
+	args = append(args, optional(s.Health))
+	args = append(args, optional(s.Description))
+	args = append(args, optional(s.DeviceType))
+
+	return c.call(ctx, "scheduler.devices.update", trimNone(args), nil)
+}
+
+// DevicesDictionaryGet returns the jinja2 device dictionary or the
+// device configuration as rendered by the server if render is set
+func (c Connection) DevicesDictionaryGet(hostname string, render bool) (string, error) {
+	return c.DevicesDictionaryGetContext(context.Background(), hostname, render)
+}
+
+// DevicesDictionaryGetContext is like DevicesDictionaryGet but uses ctx for the request
+func (c Connection) DevicesDictionaryGetContext(ctx context.Context, hostname string, render bool) (string, error) {
+	var data string
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, render)
+
+	err := c.call(ctx, "scheduler.devices.get_dictionary", args, &data)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+// DevicesDictionarySet uploads the jinja2 device dictionary
+func (c Connection) DevicesDictionarySet(hostname string, dict string) error {
+	return c.DevicesDictionarySetContext(context.Background(), hostname, dict)
+}
+
+// DevicesDictionarySetContext is like DevicesDictionarySet but uses ctx for the request
+func (c Connection) DevicesDictionarySetContext(ctx context.Context, hostname string, dict string) error {
+	var ret bool
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, dict)
+
+	err := c.call(ctx, "scheduler.devices.set_dictionary", args, &ret)
+	if err != nil {
+		return err
+	}
+	if !ret {
+		return fmt.Errorf("Failed to set device dictionary of %s", hostname)
+	}
+
+	return nil
 }
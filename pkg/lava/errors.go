@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kolo/xmlrpc"
+)
+
+// Sentinel errors to be used with errors.Is
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidRequest   = errors.New("invalid request")
+	ErrServer           = errors.New("server error")
+	ErrTransport        = errors.New("transport error")
+)
+
+// Fault holds the code and message of an XMLRPC fault.
+// For HTTP errors Code is the HTTP status code.
+type Fault struct {
+	Code    int
+	Message string
+}
+
+func (f Fault) Error() string {
+	return fmt.Sprintf("Fault(%d): %s", f.Code, f.Message)
+}
+
+// NotFoundError is returned if the requested object doesn't exist
+type NotFoundError struct {
+	Fault
+}
+
+// Is makes errors.Is(err, ErrNotFound) work
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// PermissionDeniedError is returned if authentication failed or the user
+// lacks the permission to do the request
+type PermissionDeniedError struct {
+	Fault
+}
+
+// Is makes errors.Is(err, ErrPermissionDenied) work
+func (e *PermissionDeniedError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// InvalidRequestError is returned if the server rejected the arguments or
+// doesn't know the method
+type InvalidRequestError struct {
+	Fault
+}
+
+// Is makes errors.Is(err, ErrInvalidRequest) work
+func (e *InvalidRequestError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// ServerError is returned for all other faults
+type ServerError struct {
+	Fault
+}
+
+// Is makes errors.Is(err, ErrServer) work
+func (e *ServerError) Is(target error) bool {
+	return target == ErrServer
+}
+
+// TransportError is returned if the server couldn't be reached.
+// The underlying error, like context.DeadlineExceeded, is wrapped.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrTransport) work
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport
+}
+
+// newFaultError returns the typed error matching the fault code. LAVA uses
+// HTTP status codes as fault codes, the negative ones are defined by the
+// XMLRPC specification for faults.
+func newFaultError(code int, message string) error {
+	f := Fault{Code: code, Message: message}
+	switch code {
+	case 404:
+		return &NotFoundError{f}
+	case 401, 403:
+		return &PermissionDeniedError{f}
+	case 400, -32600, -32601, -32602, -32700:
+		return &InvalidRequestError{f}
+	}
+	return &ServerError{f}
+}
+
+// faultError converts a fault returned by github.com/kolo/xmlrpc
+func faultError(err error) error {
+	var fault xmlrpc.FaultError
+	if errors.As(err, &fault) {
+		return newFaultError(fault.Code, fault.String)
+	}
+	return err
+}
+
+// httpError converts an unexpected HTTP status
+func httpError(code int, status string) error {
+	f := Fault{Code: code, Message: fmt.Sprintf("request error: bad status code - %s", status)}
+	switch code {
+	case 401, 403:
+		return &PermissionDeniedError{f}
+	}
+	return &ServerError{f}
+}
@@ -0,0 +1,99 @@
+package lava
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func faultResponse(code int, msg string) string {
+	return fmt.Sprintf(`<?xml version="1.0"?><methodResponse><fault><value><struct>`+
+		`<member><name>faultCode</name><value><int>%d</int></value></member>`+
+		`<member><name>faultString</name><value><string>%s</string></value></member>`+
+		`</struct></value></fault></methodResponse>`, code, msg)
+}
+
+func TestConnection_callErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		code   int
+	}{
+		{"not found", 200, faultResponse(404, "Job '1' was not found."), ErrNotFound, 404},
+		{"forbidden", 200, faultResponse(403, "Permission denied"), ErrPermissionDenied, 403},
+		{"unauthorized", 200, faultResponse(401, "Authentication required"), ErrPermissionDenied, 401},
+		{"bad request", 200, faultResponse(400, "Invalid job definition"), ErrInvalidRequest, 400},
+		{"unknown method", 200, faultResponse(-32601, "method not found"), ErrInvalidRequest, -32601},
+		{"fault", 200, faultResponse(500, "Internal error"), ErrServer, 500},
+		{"http unauthorized", 401, "", ErrPermissionDenied, 401},
+		{"http bad gateway", 502, "", ErrServer, 502},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.JobsShow(1)
+		srv.Close()
+
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+		for _, other := range []error{ErrNotFound, ErrPermissionDenied, ErrInvalidRequest, ErrServer, ErrTransport} {
+			if other != tt.want && errors.Is(err, other) {
+				t.Errorf("%s: error %v also matches %v", tt.name, err, other)
+			}
+		}
+
+		var code int
+		var notFound *NotFoundError
+		var denied *PermissionDeniedError
+		var invalid *InvalidRequestError
+		var server *ServerError
+		switch {
+		case errors.As(err, &notFound):
+			code = notFound.Code
+		case errors.As(err, &denied):
+			code = denied.Code
+		case errors.As(err, &invalid):
+			code = invalid.Code
+		case errors.As(err, &server):
+			code = server.Code
+		}
+		if code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestConnection_callTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.JobsShow(1)
+	var transport *TransportError
+	if !errors.As(err, &transport) || !errors.Is(err, ErrTransport) {
+		t.Errorf("error = %v, want TransportError", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.JobsShowContext(ctx, 1)
+	if !errors.Is(err, ErrTransport) || !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want TransportError wrapping context.Canceled", err)
+	}
+}
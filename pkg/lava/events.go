@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Event types as found in the last component of the event topic
+const (
+	EventTypeTestJob = "testjob"
+	EventTypeDevice  = "device"
+	EventTypeWorker  = "worker"
+	EventTypeJob     = "event"
+)
+
+// TestJobEvent is published when a test job changes its state or health
+type TestJobEvent struct {
+	Job         json.Number `json:"job" yaml:"job"`
+	Description string      `json:"description" yaml:"description"`
+	DeviceType  string      `json:"device_type" yaml:"device_type"`
+	Device      string      `json:"device" yaml:"device"`
+	State       string      `json:"state" yaml:"state"`
+	Health      string      `json:"health" yaml:"health"`
+	Submitter   string      `json:"submitter" yaml:"submitter"`
+	HealthCheck bool        `json:"health_check" yaml:"health_check"`
+	Visibility  string      `json:"visibility" yaml:"visibility"`
+	SubmitTime  string      `json:"submit_time" yaml:"submit_time"`
+	StartTime   string      `json:"start_time" yaml:"start_time"`
+	EndTime     string      `json:"end_time" yaml:"end_time"`
+}
+
+// DeviceEvent is published when a device changes its state or health
+type DeviceEvent struct {
+	Device     string      `json:"device" yaml:"device"`
+	DeviceType string      `json:"device_type" yaml:"device_type"`
+	State      string      `json:"state" yaml:"state"`
+	Health     string      `json:"health" yaml:"health"`
+	Job        json.Number `json:"job" yaml:"job"`
+}
+
+// WorkerEvent is published when a worker changes its state or health
+type WorkerEvent struct {
+	Worker string `json:"worker" yaml:"worker"`
+	State  string `json:"state" yaml:"state"`
+	Health string `json:"health" yaml:"health"`
+}
+
+// JobEvent is published by a running test job, for example using lava-test-event
+type JobEvent struct {
+	Job     json.Number `json:"job" yaml:"job"`
+	Message interface{} `json:"message" yaml:"message"`
+}
+
+// Event is a single message received on the LAVA event stream.
+// Depending on Type one of TestJob, Device, Worker or Job is set.
+type Event struct {
+	Topic    string          `json:"topic" yaml:"topic"`
+	UUID     string          `json:"uuid" yaml:"uuid"`
+	DateTime string          `json:"datetime" yaml:"datetime"`
+	Username string          `json:"username" yaml:"username"`
+	Type     string          `json:"type" yaml:"type"`
+	Data     json.RawMessage `json:"data" yaml:"-"`
+	TestJob  *TestJobEvent   `json:"testjob,omitempty" yaml:"testjob,omitempty"`
+	Device   *DeviceEvent    `json:"device,omitempty" yaml:"device,omitempty"`
+	Worker   *WorkerEvent    `json:"worker,omitempty" yaml:"worker,omitempty"`
+	Job      *JobEvent       `json:"job,omitempty" yaml:"job,omitempty"`
+}
+
+// JobNumber returns the job the event relates to as published by the server:
+// the job ID or, for multinode sub jobs, the sub_id "<id>.<index>". Use
+// JobsResolve to get the ID of a sub job. Returns "" if there is no job.
+func (e *Event) JobNumber() string {
+	switch {
+	case e.TestJob != nil:
+		return string(e.TestJob.Job)
+	case e.Device != nil:
+		return string(e.Device.Job)
+	case e.Job != nil:
+		return string(e.Job.Job)
+	}
+	return ""
+}
+
+// JobID returns the ID of the job the event relates to, or 0 if none or if
+// the job is a multinode sub job, see JobNumber
+func (e *Event) JobID() int {
+	id, err := strconv.Atoi(e.JobNumber())
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// DeviceName returns the hostname of the device the event relates to, or "" if none
+func (e *Event) DeviceName() string {
+	switch {
+	case e.TestJob != nil:
+		return e.TestJob.Device
+	case e.Device != nil:
+		return e.Device.Device
+	}
+	return ""
+}
+
+// decodeEvent decodes a message as sent by lava-publisher:
+// [topic, uuid, datetime, username, data], with data being a JSON document
+func decodeEvent(msg []byte) (*Event, error) {
+	var fields []json.RawMessage
+	err := json.Unmarshal(msg, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode event: %v", err)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Failed to decode event: expected 5 fields, got %d", len(fields))
+	}
+
+	var ev Event
+	for i, dst := range []*string{&ev.Topic, &ev.UUID, &ev.DateTime, &ev.Username} {
+		err = json.Unmarshal(fields[i], dst)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode event: %v", err)
+		}
+	}
+
+	// The payload is usually sent as a string holding a JSON document
+	data := []byte(fields[4])
+	var s string
+	if json.Unmarshal(fields[4], &s) == nil {
+		data = []byte(s)
+	}
+	ev.Data = json.RawMessage(data)
+
+	ev.Type = ev.Topic
+	if i := strings.LastIndex(ev.Topic, "."); i >= 0 {
+		ev.Type = ev.Topic[i+1:]
+	}
+
+	var dst interface{}
+	switch ev.Type {
+	case EventTypeTestJob:
+		ev.TestJob = &TestJobEvent{}
+		dst = ev.TestJob
+	case EventTypeDevice:
+		ev.Device = &DeviceEvent{}
+		dst = ev.Device
+	case EventTypeWorker:
+		ev.Worker = &WorkerEvent{}
+		dst = ev.Worker
+	case EventTypeJob:
+		ev.Job = &JobEvent{}
+		dst = ev.Job
+	default:
+		return &ev, nil
+	}
+	err = json.Unmarshal(data, dst)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode %s event: %v", ev.Type, err)
+	}
+
+	return &ev, nil
+}
+
+// eventSource delivers events to an EventListener
+type eventSource interface {
+	next() (*Event, error)
+	close() error
+}
+
+// wsSource receives events from the websocket
+type wsSource struct {
+	ws *wsConn
+}
+
+func (s wsSource) next() (*Event, error) {
+	msg, err := s.ws.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	return decodeEvent(msg)
+}
+
+func (s wsSource) close() error {
+	return s.ws.Close()
+}
+
+// chanSource receives events from a channel
+type chanSource struct {
+	events <-chan *Event
+	done   chan struct{}
+}
+
+func (s chanSource) next() (*Event, error) {
+	select {
+	case ev, ok := <-s.events:
+		if !ok {
+			return nil, io.EOF
+		}
+		return ev, nil
+	case <-s.done:
+		return nil, io.EOF
+	}
+}
+
+func (s chanSource) close() error {
+	close(s.done)
+	return nil
+}
+
+// EventListener receives events from the LAVA event stream
+type EventListener struct {
+	ctx       context.Context
+	src       eventSource
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newEventListener returns a listener, which is closed once ctx is done
+func newEventListener(ctx context.Context, src eventSource) *EventListener {
+	l := &EventListener{ctx: ctx, src: src, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.Close()
+		case <-l.done:
+		}
+	}()
+
+	return l
+}
+
+// NewEventListener returns a listener delivering the events sent on the channel.
+// Closing the channel ends the stream. This allows other implementations of
+// Client, like fakes, to provide events.
+func NewEventListener(ctx context.Context, events <-chan *Event) *EventListener {
+	return newEventListener(ctx, chanSource{events: events, done: make(chan struct{})})
+}
+
+// EventsURI returns the websocket URI of the event stream belonging to the
+// XMLRPC URI of the connection
+func (c Connection) EventsURI() (string, error) {
+	u, err := url.Parse(c.uri)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse URI: %v", err)
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("Unsupported scheme %s", u.Scheme)
+	}
+	u.User = nil
+	u.Path = "/ws/"
+	u.RawQuery = ""
+
+	return u.String(), nil
+}
+
+// EventsListen connects to the event stream of the server.
+// Proxies are not supported.
+func (c Connection) EventsListen() (*EventListener, error) {
+	return c.EventsListenContext(context.Background())
+}
+
+// EventsListenContext is like EventsListen, but the listener is closed
+// once ctx is done
+func (c Connection) EventsListenContext(ctx context.Context) (*EventListener, error) {
+	uri, err := c.EventsURI()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.EventsListenURIContext(ctx, uri)
+}
+
+// EventsListenURI connects to the event stream at the given ws:// or wss:// URI.
+// The ZMQ publisher of the server, tcp://, isn't supported.
+func (c Connection) EventsListenURI(uri string) (*EventListener, error) {
+	return c.EventsListenURIContext(context.Background(), uri)
+}
+
+// EventsListenURIContext is like EventsListenURI, but the listener is closed
+// once ctx is done
+func (c Connection) EventsListenURIContext(ctx context.Context, uri string) (*EventListener, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI: %v", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("Unsupported event stream %s: only the websocket at ws:// or wss://<server>/ws/ "+
+			"is supported, not the ZMQ publisher", uri)
+	}
+
+	var tlsConfig *tls.Config
+	if c.opt.Transport != nil {
+		tlsConfig = c.opt.Transport.TLSClientConfig
+	}
+	ws, err := dialWebsocket(ctx, uri, tlsConfig)
+	if err != nil {
+		return nil, &TransportError{Err: fmt.Errorf("Failed to connect to event stream %s: %w", uri, err)}
+	}
+
+	return newEventListener(ctx, wsSource{ws: ws}), nil
+}
+
+// Next blocks until the next event has been received.
+// Returns io.EOF when the server closed the stream and the context's error
+// once the context of the listener is done.
+func (l *EventListener) Next() (*Event, error) {
+	ev, err := l.src.next()
+	if l.ctx.Err() != nil {
+		return nil, l.ctx.Err()
+	}
+
+	return ev, err
+}
+
+// Close closes the event stream. It's safe to call Close multiple times.
+func (l *EventListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.src.close()
+	})
+	return err
+}
@@ -0,0 +1,172 @@
+package lava
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func eventMessage(t *testing.T, topic string, data interface{}) []byte {
+	d, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := json.Marshal([]interface{}{topic, "uuid", "2020-01-01T00:00:00", "lava", string(d)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// newEventServer returns a websocket server sending a ping, the given messages
+// and a close message. Pongs and the close message received from the client are
+// passed to got.
+func newEventServer(t *testing.T, messages [][]byte, got chan<- string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/" {
+			http.NotFound(w, r)
+			return
+		}
+		var upgrader websocket.Upgrader
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		conn.SetPongHandler(func(data string) error {
+			got <- "pong " + data
+			return nil
+		})
+
+		conn.WriteControl(websocket.PingMessage, []byte("hello"), time.Now().Add(time.Second))
+		for _, m := range messages {
+			conn.WriteMessage(websocket.TextMessage, m)
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				if _, ok := err.(*websocket.CloseError); ok {
+					got <- "close"
+				}
+				close(got)
+				return
+			}
+		}
+	}))
+}
+
+func TestEventsListen(t *testing.T) {
+	job := eventMessage(t, "org.lavasoftware.testjob", map[string]interface{}{
+		"job": "42", "state": "Finished", "health": "Complete", "device": "qemu01",
+	})
+	device := eventMessage(t, "org.lavasoftware.device", map[string]interface{}{
+		"device": "qemu01", "state": "Idle", "health": "Good",
+	})
+	worker := eventMessage(t, "org.lavasoftware.worker", map[string]interface{}{
+		"worker": "worker01", "state": "Online", "health": "Active",
+	})
+
+	got := make(chan string, 10)
+	srv := newEventServer(t, [][]byte{job, device, worker}, got)
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := c.EventsListen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ev, err := l.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != EventTypeTestJob || ev.TestJob == nil {
+		t.Fatalf("expected testjob event, got %+v", ev)
+	}
+	if ev.JobID() != 42 || ev.TestJob.State != "Finished" || ev.DeviceName() != "qemu01" {
+		t.Errorf("unexpected testjob event %+v", ev.TestJob)
+	}
+
+	ev, err = l.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Device == nil || ev.Device.Health != "Good" || ev.DeviceName() != "qemu01" {
+		t.Errorf("unexpected device event %+v", ev)
+	}
+
+	ev, err = l.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Worker == nil || ev.Worker.Worker != "worker01" || ev.JobID() != 0 {
+		t.Errorf("unexpected worker event %+v", ev)
+	}
+
+	_, err = l.Next()
+	if err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	if pong := <-got; pong != "pong hello" {
+		t.Errorf("expected pong, got %q", pong)
+	}
+	if c := <-got; c != "close" {
+		t.Errorf("expected close, got %q", c)
+	}
+}
+
+func TestEventsListenURI_unsupported(t *testing.T) {
+	c, err := ConnectByURI("http://localhost/RPC2", "", ConnectionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.EventsListenURI("tcp://localhost:5500")
+	if err == nil || !strings.Contains(err.Error(), "ZMQ") {
+		t.Errorf("EventsListenURI() error = %v, want ZMQ not supported", err)
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	tests := []struct {
+		msg     string
+		typ     string
+		job     int
+		number  string
+		wantErr bool
+	}{
+		{`["org.lava.testjob", "u", "d", "lava", "{\"job\": 12}"]`, EventTypeTestJob, 12, "12", false},
+		{`["org.lava.testjob", "u", "d", "lava", "{\"job\": \"12.1\"}"]`, EventTypeTestJob, 0, "12.1", false},
+		{`["org.lava.event", "u", "d", "lava", {"job": "7", "message": "boot"}]`, EventTypeJob, 7, "7", false},
+		{`["org.lava.unknown", "u", "d", "lava", "{}"]`, "unknown", 0, "", false},
+		{`["org.lava.testjob", "u", "d", "lava"]`, "", 0, "", true},
+		{`["org.lava.device", "u", "d", "lava", "{\"device\": 1}"]`, "", 0, "", true},
+		{`not json`, "", 0, "", true},
+	}
+	for _, tt := range tests {
+		ev, err := decodeEvent([]byte(tt.msg))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("decodeEvent(%s) error = %v, wantErr %v", tt.msg, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if ev.Type != tt.typ || ev.JobID() != tt.job || ev.JobNumber() != tt.number {
+			t.Errorf("decodeEvent(%s) = type %s job %d (%s), want %s %d (%s)", tt.msg,
+				ev.Type, ev.JobID(), ev.JobNumber(), tt.typ, tt.job, tt.number)
+		}
+	}
+}
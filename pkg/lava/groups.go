@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import "context"
+
+// Group represents a single entry as returned by LAVA XMLRPC auth.groups.list
+type Group struct {
+	Name string `xmlrpc:"name" json:"name" yaml:"name"`
+}
+
+// GroupDetail represents data as returned by LAVA XMLRPC auth.groups.show
+type GroupDetail struct {
+	ID          int      `xmlrpc:"id" json:"id" yaml:"id"`
+	Name        string   `xmlrpc:"name" json:"name" yaml:"name"`
+	Permissions []string `xmlrpc:"permissions" json:"permissions" yaml:"permissions"`
+	Users       []string `xmlrpc:"users" json:"users" yaml:"users"`
+}
+
+// Permission represents data as returned by LAVA XMLRPC auth.groups.perms.list
+type Permission struct {
+	App      string `xmlrpc:"app" json:"app" yaml:"app"`
+	Codename string `xmlrpc:"codename" json:"codename" yaml:"codename"`
+	Name     string `xmlrpc:"name" json:"name" yaml:"name"`
+}
+
+func (c Connection) GroupsList() ([]Group, error) {
+	return c.GroupsListContext(context.Background())
+}
+
+// GroupsListContext is like GroupsList but uses ctx for the request
+func (c Connection) GroupsListContext(ctx context.Context) ([]Group, error) {
+	var names []string
+	var ret []Group
+
+	err := c.call(ctx, "auth.groups.list", nil, &names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range names {
+		ret = append(ret, Group{Name: n})
+	}
+
+	return ret, nil
+}
+
+func (c Connection) GroupsShow(name string) (*GroupDetail, error) {
+	return c.GroupsShowContext(context.Background(), name)
+}
+
+// GroupsShowContext is like GroupsShow but uses ctx for the request
+func (c Connection) GroupsShowContext(ctx context.Context, name string) (*GroupDetail, error) {
+	var ret GroupDetail
+
+	err := c.call(ctx, "auth.groups.show", name, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (c Connection) GroupsPermsList(name string) ([]Permission, error) {
+	return c.GroupsPermsListContext(context.Background(), name)
+}
+
+// GroupsPermsListContext is like GroupsPermsList but uses ctx for the request
+func (c Connection) GroupsPermsListContext(ctx context.Context, name string) ([]Permission, error) {
+	var ret []Permission
+
+	err := c.call(ctx, "auth.groups.perms.list", name, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c Connection) GroupsPermsAdd(name string, app string, codename string) error {
+	return c.GroupsPermsAddContext(context.Background(), name, app, codename)
+}
+
+// GroupsPermsAddContext is like GroupsPermsAdd but uses ctx for the request
+func (c Connection) GroupsPermsAddContext(ctx context.Context, name string, app string, codename string) error {
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, app)
+	args = append(args, codename)
+
+	return c.call(ctx, "auth.groups.perms.add", args, nil)
+}
+
+func (c Connection) GroupsPermsDelete(name string, app string, codename string) error {
+	return c.GroupsPermsDeleteContext(context.Background(), name, app, codename)
+}
+
+// GroupsPermsDeleteContext is like GroupsPermsDelete but uses ctx for the request
+func (c Connection) GroupsPermsDeleteContext(ctx context.Context, name string, app string, codename string) error {
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, app)
+	args = append(args, codename)
+
+	return c.call(ctx, "auth.groups.perms.delete", args, nil)
+}
@@ -7,11 +7,35 @@ import (
 )
 
 type Indentity struct {
-	Name     string
-	Token    string
-	URI      string
-	Username string
-	Proxy    string
+	Name       string
+	Token      string
+	URI        string
+	Username   string
+	Proxy      string
+	CACert     string
+	ClientCert string
+	ClientKey  string
+	Insecure   bool
+	Timeout    int
+	Auth       string
+	API        string
+}
+
+func newIdentity(name string, c ConfigIndentity) Indentity {
+	return Indentity{
+		Name:       name,
+		Token:      c.Token,
+		URI:        c.URI,
+		Username:   c.Username,
+		Proxy:      c.Proxy,
+		CACert:     c.CACert,
+		ClientCert: c.ClientCert,
+		ClientKey:  c.ClientKey,
+		Insecure:   c.Insecure,
+		Timeout:    c.Timeout,
+		Auth:       c.Auth,
+		API:        c.API,
+	}
 }
 
 func IdentitiesList() ([]Indentity, error) {
@@ -21,13 +45,7 @@ func IdentitiesList() ([]Indentity, error) {
 		return nil, err
 	}
 	for k, v := range configs {
-		ret = append(ret, Indentity{
-			k,
-			v.Token,
-			v.URI,
-			v.Username,
-			v.Proxy,
-		})
+		ret = append(ret, newIdentity(k, v))
 	}
 
 	return ret, nil
@@ -51,6 +69,13 @@ func IdentitiesAdd(id Indentity) error {
 	c.Token = id.Token
 	c.Username = id.Username
 	c.Proxy = id.Proxy
+	c.CACert = id.CACert
+	c.ClientCert = id.ClientCert
+	c.ClientKey = id.ClientKey
+	c.Insecure = id.Insecure
+	c.Timeout = id.Timeout
+	c.Auth = id.Auth
+	c.API = id.API
 
 	configs[id.Name] = c
 
@@ -65,13 +90,7 @@ func IdentitiesShow(name string) (*Indentity, error) {
 	}
 	for k, v := range configs {
 		if k == name {
-			ret = Indentity{
-				k,
-				v.Token,
-				v.URI,
-				v.Username,
-				v.Proxy,
-			}
+			ret = newIdentity(k, v)
 			return &ret, nil
 		}
 	}
@@ -3,8 +3,13 @@
 package lava
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -12,15 +17,24 @@ import (
 
 // JobsListing represents data as returned by LAVA XMLRPC scheduler.jobs.list
 type JobsListing struct {
-	Description string `xmlrpm:"description"`
-	DeviceType  string `xmlrpc:"device_type"`
-	Health      string `xmlrpc:"health"`
-	ID          int    `xmlrpc:"id"`
-	State       string `xmlrpc:"state"`
-	Submitter   string `xmlrpc:"submitter"`
+	Description string    `xmlrpc:"description"`
+	DeviceType  string    `xmlrpc:"device_type"`
+	Health      string    `xmlrpc:"health"`
+	ID          int       `xmlrpc:"id"`
+	State       string    `xmlrpc:"state"`
+	Submitter   string    `xmlrpc:"submitter"`
+	SubmitTime  time.Time `xmlrpc:"submit_time"`
 }
 
 func (c Connection) JobsList(state string, health string, start int, limit int) ([]JobsListing, error) {
+	return c.JobsListContext(context.Background(), state, health, start, limit)
+}
+
+// JobsListContext is like JobsList but uses ctx for the request
+func (c Connection) JobsListContext(ctx context.Context, state string, health string, start int, limit int) ([]JobsListing, error) {
+	if c.rest != "" {
+		return c.restJobs(ctx, JobsFilter{State: state, Health: health}, start, limit)
+	}
 	var ret []JobsListing
 
 	var args []interface{}
@@ -29,7 +43,7 @@ func (c Connection) JobsList(state string, health string, start int, limit int)
 	args = append(args, start)
 	args = append(args, limit)
 
-	err := c.con.Call("scheduler.jobs.list", args, &ret)
+	err := c.call(ctx, "scheduler.jobs.list", args, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +51,302 @@ func (c Connection) JobsList(state string, health string, start int, limit int)
 	return ret, nil
 }
 
+// JobsIterator lazily walks all jobs matching a state and health filter,
+// fetching one page at a time. The server returns the newest jobs first.
+//
+//	it := c.JobsIter("", "", 100)
+//	for it.Next() {
+//		job := it.Job()
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+type JobsIterator struct {
+	list     func(offset int, n int) ([]JobsListing, error)
+	pageSize int
+	start    int
+	page     []JobsListing
+	pos      int
+	last     bool
+	err      error
+}
+
+// JobsIter returns an iterator over all jobs matching state and health.
+// Empty filters match all jobs.
+func (c Connection) JobsIter(state string, health string, pageSize int) *JobsIterator {
+	return c.JobsIterFromContext(context.Background(), state, health, 0, pageSize)
+}
+
+// JobsIterContext is like JobsIter but uses ctx for all page requests
+func (c Connection) JobsIterContext(ctx context.Context, state string, health string, pageSize int) *JobsIterator {
+	return c.JobsIterFromContext(ctx, state, health, 0, pageSize)
+}
+
+// JobsIterFrom returns an iterator starting at offset start
+func (c Connection) JobsIterFrom(state string, health string, start int, pageSize int) *JobsIterator {
+	return c.JobsIterFromContext(context.Background(), state, health, start, pageSize)
+}
+
+// JobsIterFromContext is like JobsIterFrom but uses ctx for all page requests
+func (c Connection) JobsIterFromContext(ctx context.Context, state string, health string, start int, pageSize int) *JobsIterator {
+	return NewJobsIterator(ctx, c, state, health, start, pageSize)
+}
+
+// NewJobsIterator returns an iterator fetching pages using c.JobsListContext.
+// This allows other implementations of Client to provide JobsIter.
+func NewJobsIterator(ctx context.Context, c Client, state string, health string, start int, pageSize int) *JobsIterator {
+	return newJobsIterator(start, pageSize, func(offset int, n int) ([]JobsListing, error) {
+		return c.JobsListContext(ctx, state, health, offset, n)
+	})
+}
+
+// newJobsIterator returns an iterator fetching pages of pageSize jobs using list
+func newJobsIterator(start int, pageSize int, list func(offset int, n int) ([]JobsListing, error)) *JobsIterator {
+	if pageSize < 1 {
+		pageSize = 25
+	}
+	return &JobsIterator{
+		list:     list,
+		pageSize: pageSize,
+		start:    start,
+	}
+}
+
+// Next advances to the next job. Returns false at the end of the list or on error.
+func (it *JobsIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos+1 < len(it.page) {
+		it.pos++
+		return true
+	}
+	if it.last {
+		return false
+	}
+
+	it.page, it.err = it.list(it.start, it.pageSize)
+	if it.err != nil {
+		return false
+	}
+	it.start += len(it.page)
+	it.pos = 0
+	if len(it.page) < it.pageSize {
+		it.last = true
+	}
+
+	return len(it.page) > 0
+}
+
+// Job returns the current job
+func (it *JobsIterator) Job() JobsListing {
+	return it.page[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *JobsIterator) Err() error {
+	return it.err
+}
+
+// JobsFilter selects jobs by JobsListFiltered. Empty fields match all jobs.
+type JobsFilter struct {
+	// State is one of SUBMITTED, SCHEDULING, SCHEDULED, RUNNING, CANCELING, FINISHED
+	State string
+	// Health is one of UNKNOWN, COMPLETE, INCOMPLETE, CANCELED
+	Health     string
+	Submitter  string
+	DeviceType string
+	// SubmittedAfter only matches jobs submitted at or after the given time
+	SubmittedAfter time.Time
+	// SubmittedBefore only matches jobs submitted before the given time
+	SubmittedBefore time.Time
+	// Description only matches jobs whose description matches the expression
+	Description *regexp.Regexp
+}
+
+// Match returns true if the job matches all criteria of the filter
+func (f JobsFilter) Match(job JobsListing) bool {
+	if f.State != "" && !strings.EqualFold(f.State, job.State) {
+		return false
+	}
+	if f.Health != "" && !strings.EqualFold(f.Health, job.Health) {
+		return false
+	}
+	if f.Submitter != "" && f.Submitter != job.Submitter {
+		return false
+	}
+	if f.DeviceType != "" && f.DeviceType != job.DeviceType {
+		return false
+	}
+	if !f.SubmittedAfter.IsZero() && job.SubmitTime.Before(f.SubmittedAfter) {
+		return false
+	}
+	if !f.SubmittedBefore.IsZero() && !job.SubmitTime.Before(f.SubmittedBefore) {
+		return false
+	}
+	if f.Description != nil && !f.Description.MatchString(job.Description) {
+		return false
+	}
+
+	return true
+}
+
+// jobsPageSize is the number of jobs fetched at once when filtering locally
+const jobsPageSize = 100
+
+// filterJobs walks the jobs of the iterator, newest job first, and returns
+// up to limit jobs matching the filter after skipping the first start matches.
+// A limit of zero returns all matching jobs.
+func filterJobs(filter JobsFilter, start int, limit int, it *JobsIterator) ([]JobsListing, error) {
+	ret := []JobsListing{}
+	for it.Next() {
+		job := it.Job()
+		// All remaining jobs are older
+		if !filter.SubmittedAfter.IsZero() && job.SubmitTime.Before(filter.SubmittedAfter) {
+			return ret, nil
+		}
+		if !filter.Match(job) {
+			continue
+		}
+		if start > 0 {
+			start--
+			continue
+		}
+		ret = append(ret, job)
+		if limit > 0 && len(ret) == limit {
+			return ret, nil
+		}
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	return ret, nil
+}
+
+// JobsListFiltered returns up to limit jobs matching the filter, newest first,
+// skipping the first start matches. A limit of zero returns all matching jobs.
+// The REST API filters on the server, the XMLRPC API lists all jobs of the
+// given state and health and filters locally. Filtering by submit time
+// requests the verbose XMLRPC listing. Filtering locally walks the
+// entire job history if fewer than limit jobs match. Set SubmittedAfter to
+// stop at the first older job.
+func (c Connection) JobsListFiltered(filter JobsFilter, start int, limit int) ([]JobsListing, error) {
+	return c.JobsListFilteredContext(context.Background(), filter, start, limit)
+}
+
+// JobsListFilteredContext is like JobsListFiltered but uses ctx for the requests
+func (c Connection) JobsListFilteredContext(ctx context.Context, filter JobsFilter, start int, limit int) ([]JobsListing, error) {
+	if c.rest != "" {
+		return c.restJobsList(ctx, filter, start, limit)
+	}
+	if filter.SubmittedAfter.IsZero() && filter.SubmittedBefore.IsZero() {
+		it := c.JobsIterContext(ctx, filter.State, filter.Health, jobsPageSize)
+		return filterJobs(filter, start, limit, it)
+	}
+	it := newJobsIterator(0, jobsPageSize, func(offset int, n int) ([]JobsListing, error) {
+		return c.jobsListVerbose(ctx, filter.State, filter.Health, offset, n)
+	})
+	return filterJobs(filter, start, limit, it)
+}
+
+// jobsListVerbose is like JobsListContext but requests the verbose listing,
+// which includes the submit time of the jobs
+func (c Connection) jobsListVerbose(ctx context.Context, state string, health string, start int, limit int) ([]JobsListing, error) {
+	var ret []JobsListing
+
+	// A since of zero minutes doesn't filter the jobs
+	args := []interface{}{state, health, start, limit, 0, true}
+	err := c.call(ctx, "scheduler.jobs.list", args, &ret)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range ret {
+		// Otherwise filtering by submit time silently returns wrong jobs
+		if j.SubmitTime.IsZero() {
+			return nil, fmt.Errorf("The server doesn't return the submit time of job %d, "+
+				"filtering by submit time requires the REST API", j.ID)
+		}
+	}
+
+	return ret, nil
+}
+
+// JobsQueueListing represents data as returned by LAVA XMLRPC scheduler.jobs.queue
+type JobsQueueListing struct {
+	// Multinode sub jobs are identified by SubID, their sub_id "<id>.<index>",
+	// with ID being the ID of the first sub job. SubID is empty otherwise.
+	ID                  int       `xmlrpc:"id" json:"id" yaml:"id"`
+	SubID               string    `xmlrpc:"-" json:"sub_id,omitempty" yaml:"sub_id,omitempty"`
+	Description         string    `xmlrpc:"description" json:"description" yaml:"description"`
+	RequestedDeviceType string    `xmlrpc:"requested_device_type" json:"requested_device_type" yaml:"requested_device_type"`
+	Submitter           string    `xmlrpc:"submitter" json:"submitter" yaml:"submitter"`
+	SubmitTime          time.Time `xmlrpc:"submit_time" json:"submit_time" yaml:"submit_time"`
+	Priority            int       `xmlrpc:"priority" json:"priority" yaml:"priority"`
+	Tags                []string  `xmlrpc:"tags" json:"tags" yaml:"tags"`
+}
+
+// JobsQueue returns the queued jobs for the given device-types.
+// All device-types are returned if deviceTypes is empty.
+func (c Connection) JobsQueue(deviceTypes []string, start int, limit int) ([]JobsQueueListing, error) {
+	return c.JobsQueueContext(context.Background(), deviceTypes, start, limit)
+}
+
+// JobsQueueContext is like JobsQueue but uses ctx for the request
+func (c Connection) JobsQueueContext(ctx context.Context, deviceTypes []string, start int, limit int) ([]JobsQueueListing, error) {
+	// Multinode jobs use a string as ID
+	var xmlRet []struct {
+		ID                  interface{} `xmlrpc:"id"`
+		Description         string      `xmlrpc:"description"`
+		RequestedDeviceType string      `xmlrpc:"requested_device_type"`
+		Submitter           string      `xmlrpc:"submitter"`
+		SubmitTime          time.Time   `xmlrpc:"submit_time"`
+		Priority            int         `xmlrpc:"priority"`
+		Tags                []string    `xmlrpc:"tags"`
+	}
+	var ret []JobsQueueListing
+
+	var args []interface{}
+	if len(deviceTypes) > 0 {
+		args = append(args, deviceTypes)
+	} else {
+		args = append(args, none)
+	}
+	args = append(args, start)
+	args = append(args, limit)
+
+	err := c.call(ctx, "scheduler.jobs.queue", args, &xmlRet)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range xmlRet {
+		numbers, err := jobNumbers(j.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range numbers {
+			id, index, _ := splitSubID(n)
+			subID := ""
+			if index >= 0 {
+				subID = n
+			}
+			ret = append(ret, JobsQueueListing{
+				ID:                  id,
+				SubID:               subID,
+				Description:         j.Description,
+				RequestedDeviceType: j.RequestedDeviceType,
+				Submitter:           j.Submitter,
+				SubmitTime:          j.SubmitTime,
+				Priority:            j.Priority,
+				Tags:                j.Tags,
+			})
+		}
+	}
+
+	return ret, nil
+}
+
 // JobState represents data as returned by LAVA XMLRPC scheduler.jobs.show
 type JobState struct {
 	Description    string    `xmlrpc:"description" yaml:"description" json:"description"`
@@ -58,9 +368,21 @@ type JobState struct {
 }
 
 func (c Connection) JobsShow(id int) (*JobState, error) {
+	return c.JobsShowContext(context.Background(), id)
+}
+
+// JobsShowContext is like JobsShow but uses ctx for the request
+func (c Connection) JobsShowContext(ctx context.Context, id int) (*JobState, error) {
+	if c.rest != "" {
+		j, err := c.restJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return j.state(), nil
+	}
 	var ret JobState
 
-	err := c.con.Call("scheduler.jobs.show", id, &ret)
+	err := c.call(ctx, "scheduler.jobs.show", id, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -220,9 +542,21 @@ type NotifyStruct struct {
 type JobDefintion string
 
 func (c Connection) JobsDefinition(id int) (JobDefintion, error) {
+	return c.JobsDefinitionContext(context.Background(), id)
+}
+
+// JobsDefinitionContext is like JobsDefinition but uses ctx for the request
+func (c Connection) JobsDefinitionContext(ctx context.Context, id int) (JobDefintion, error) {
+	if c.rest != "" {
+		j, err := c.restJob(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return JobDefintion(j.Definition), nil
+	}
 	var ret JobDefintion
 
-	err := c.con.Call("scheduler.jobs.definition", id, &ret)
+	err := c.call(ctx, "scheduler.jobs.definition", id, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -233,12 +567,17 @@ func (c Connection) JobsDefinition(id int) (JobDefintion, error) {
 type JobErrors map[string]interface{}
 
 func (c Connection) JobsValidate(def string, strict bool) (JobErrors, error) {
+	return c.JobsValidateContext(context.Background(), def, strict)
+}
+
+// JobsValidateContext is like JobsValidate but uses ctx for the request
+func (c Connection) JobsValidateContext(ctx context.Context, def string, strict bool) (JobErrors, error) {
 	var ret JobErrors
 	var args []interface{}
 	args = append(args, def)
 	args = append(args, strict)
 
-	err := c.con.Call("scheduler.jobs.validate", args, &ret)
+	err := c.call(ctx, "scheduler.jobs.validate", args, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -246,28 +585,38 @@ func (c Connection) JobsValidate(def string, strict bool) (JobErrors, error) {
 	return ret, nil
 }
 
-func (c Connection) JobsSubmitString(def string) ([]int, error) {
-	var ret []int
-	var xmlRet interface{}
-
-	err := c.con.Call("scheduler.jobs.submit", def, &xmlRet)
-	if err != nil {
-		return nil, err
-	}
+// jobNumbers converts the job ID or list of job IDs (multinode) returned by
+// the server to strings. Multinode sub jobs are returned as their sub_id
+// "<id>.<index>", where id is the ID of the first sub job.
+func jobNumbers(xmlRet interface{}) ([]string, error) {
+	var ret []string
 
 	switch x := xmlRet.(type) {
+	case []interface{}:
+		for i := range x {
+			n, err := jobNumbers(x[i])
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, n...)
+		}
 	case []int:
 		for _, i := range x {
-			ret = append(ret, int(x[i]))
+			ret = append(ret, strconv.Itoa(i))
 		}
 	case []int64:
 		for _, i := range x {
-			ret = append(ret, int(x[i]))
+			ret = append(ret, strconv.FormatInt(i, 10))
 		}
 	case int:
-		ret = append(ret, int(x))
+		ret = append(ret, strconv.Itoa(x))
 	case int64:
-		ret = append(ret, int(x))
+		ret = append(ret, strconv.FormatInt(x, 10))
+	case string:
+		if _, _, err := splitSubID(x); err != nil {
+			return nil, err
+		}
+		ret = append(ret, x)
 	default:
 		return nil, fmt.Errorf("Got unexpected type: %T", x)
 	}
@@ -275,25 +624,181 @@ func (c Connection) JobsSubmitString(def string) ([]int, error) {
 	return ret, nil
 }
 
+// splitSubID splits a job ID or multinode sub_id "<id>.<index>".
+// The index is -1 for plain job IDs.
+func splitSubID(n string) (id int, index int, err error) {
+	index = -1
+	parts := strings.SplitN(n, ".", 2)
+	id, err = strconv.Atoi(parts[0])
+	if err == nil && len(parts) == 2 {
+		index, err = strconv.Atoi(parts[1])
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("Got unexpected job ID: %s", n)
+	}
+	return id, index, nil
+}
+
+// jobIDs converts the job IDs returned by the server. The sub_ids of
+// multinode sub jobs are resolved to the ID of each sub job.
+func (c Connection) jobIDs(ctx context.Context, xmlRet interface{}) ([]int, error) {
+	numbers, err := jobNumbers(xmlRet)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []int
+	for _, n := range numbers {
+		id, err := c.JobsResolveContext(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, id)
+	}
+
+	return ret, nil
+}
+
+// JobsResolve returns the ID of the job with the given ID or multinode sub_id,
+// like "123.1". Only sub_ids require a request.
+func (c Connection) JobsResolve(number string) (int, error) {
+	return c.JobsResolveContext(context.Background(), number)
+}
+
+// JobsResolveContext is like JobsResolve but uses ctx for the request
+func (c Connection) JobsResolveContext(ctx context.Context, number string) (int, error) {
+	id, index, err := splitSubID(number)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		return id, nil
+	}
+
+	var ret JobState
+	err = c.call(ctx, "scheduler.jobs.show", number, &ret)
+	if err != nil {
+		return 0, err
+	}
+
+	return ret.ID, nil
+}
+
+func (c Connection) JobsSubmitString(def string) ([]int, error) {
+	return c.JobsSubmitStringContext(context.Background(), def)
+}
+
+// JobsSubmitStringContext is like JobsSubmitString but uses ctx for the request
+func (c Connection) JobsSubmitStringContext(ctx context.Context, def string) ([]int, error) {
+	var xmlRet interface{}
+
+	err := c.call(ctx, "scheduler.jobs.submit", def, &xmlRet)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.jobIDs(ctx, xmlRet)
+}
+
 func (c Connection) JobsSubmit(def *JobStruct) ([]int, error) {
+	return c.JobsSubmitContext(context.Background(), def)
+}
+
+// JobsSubmitContext is like JobsSubmit but uses ctx for the request
+func (c Connection) JobsSubmitContext(ctx context.Context, def *JobStruct) ([]int, error) {
 
 	yaml, err := yaml.Marshal(def)
 	if err != nil {
 		return []int{-1}, err
 	}
-	return c.JobsSubmitString(string(yaml))
+	return c.JobsSubmitStringContext(ctx, string(yaml))
+}
+
+// JobConfiguration represents data as returned by LAVA XMLRPC scheduler.jobs.configuration
+type JobConfiguration struct {
+	Definition string
+	Device     string
+	Dispatcher string
+	Env        string
+	EnvDut     string
+}
+
+// JobsConfiguration returns the configuration the dispatcher got for the job.
+// Documents not available on the server are left empty.
+func (c Connection) JobsConfiguration(id int) (*JobConfiguration, error) {
+	return c.JobsConfigurationContext(context.Background(), id)
+}
+
+// JobsConfigurationContext is like JobsConfiguration but uses ctx for the request
+func (c Connection) JobsConfigurationContext(ctx context.Context, id int) (*JobConfiguration, error) {
+	var ret []interface{}
+
+	err := c.call(ctx, "scheduler.jobs.configuration", id, &ret)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != 5 {
+		return nil, fmt.Errorf("Unexpected server response")
+	}
+
+	var docs [5]string
+	for i := range ret {
+		if ret[i] == nil {
+			continue
+		}
+		doc, ok := ret[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid server response")
+		}
+		docs[i] = doc
+	}
+
+	return &JobConfiguration{
+		Definition: docs[0],
+		Device:     docs[1],
+		Dispatcher: docs[2],
+		Env:        docs[3],
+		EnvDut:     docs[4],
+	}, nil
+}
+
+// JobsResubmit resubmits the job and returns the new job IDs
+func (c Connection) JobsResubmit(id int) ([]int, error) {
+	return c.JobsResubmitContext(context.Background(), id)
+}
+
+// JobsResubmitContext is like JobsResubmit but uses ctx for the request
+func (c Connection) JobsResubmitContext(ctx context.Context, id int) ([]int, error) {
+	var xmlRet interface{}
+
+	err := c.call(ctx, "scheduler.jobs.resubmit", id, &xmlRet)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.jobIDs(ctx, xmlRet)
 }
 
 func (c Connection) JobsCancel(id int) error {
+	return c.JobsCancelContext(context.Background(), id)
+}
 
-	err := c.con.Call("scheduler.jobs.cancel", id, nil)
+// JobsCancelContext is like JobsCancel but uses ctx for the request
+func (c Connection) JobsCancelContext(ctx context.Context, id int) error {
+
+	err := c.call(ctx, "scheduler.jobs.cancel", id, nil)
 
 	return err
 }
 
 func (c Connection) JobsFail(id int) error {
+	return c.JobsFailContext(context.Background(), id)
+}
+
+// JobsFailContext is like JobsFail but uses ctx for the request
+func (c Connection) JobsFailContext(ctx context.Context, id int) error {
 
-	err := c.con.Call("scheduler.jobs.fail", id, nil)
+	err := c.call(ctx, "scheduler.jobs.fail", id, nil)
 
 	return err
 }
@@ -308,13 +813,41 @@ type JobsLogs struct {
 	Finished bool
 	Data     string
 	Decoded  []JobLogsDecoded
+	// Lines is the number of complete log lines in Data
+	Lines int
 }
 
 func (c Connection) JobsLogs(id int, raw bool) (*JobsLogs, error) {
+	return c.JobsLogsContext(context.Background(), id, raw)
+}
+
+// JobsLogsContext is like JobsLogs but uses ctx for the request
+func (c Connection) JobsLogsContext(ctx context.Context, id int, raw bool) (*JobsLogs, error) {
+	return c.JobsLogsRangeContext(ctx, id, 0, 0, raw)
+}
+
+// JobsLogsRange returns the log lines from start up to, but not including, end.
+// An end of zero returns all lines up to the end of the log. An incomplete
+// last line of a running job is dropped, use start+Lines to fetch the next lines.
+func (c Connection) JobsLogsRange(id int, start int, end int, raw bool) (*JobsLogs, error) {
+	return c.JobsLogsRangeContext(context.Background(), id, start, end, raw)
+}
+
+// JobsLogsRangeContext is like JobsLogsRange but uses ctx for the request
+func (c Connection) JobsLogsRangeContext(ctx context.Context, id int, start int, end int, raw bool) (*JobsLogs, error) {
 	var ret []interface{}
 	var ret2 JobsLogs
 
-	err := c.con.Call("scheduler.jobs.logs", id, &ret)
+	var args []interface{}
+	args = append(args, id)
+	if start > 0 || end > 0 {
+		args = append(args, start)
+	}
+	if end > 0 {
+		args = append(args, end)
+	}
+
+	err := c.call(ctx, "scheduler.jobs.logs", args, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -336,7 +869,14 @@ func (c Connection) JobsLogs(id int, raw bool) (*JobsLogs, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !finished {
+		decoded = decoded[:bytes.LastIndexByte(decoded, '\n')+1]
+	}
 	ret2.Data = string(decoded)
+	ret2.Lines = bytes.Count(decoded, []byte("\n"))
+	if len(decoded) > 0 && decoded[len(decoded)-1] != '\n' {
+		ret2.Lines++
+	}
 
 	if raw {
 		return &ret2, nil
@@ -0,0 +1,225 @@
+package lava
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// xmlrpcResponse wraps a single XMLRPC value into a method response
+func xmlrpcResponse(value string) []byte {
+	return []byte(`<?xml version="1.0"?><methodResponse><params><param>` +
+		value + `</param></params></methodResponse>`)
+}
+
+// newMultinodeServer returns a server resubmitting and submitting jobs as the
+// multinode job 123 with the sub jobs 123.0 and 123.1, having the IDs 123 and 124
+func newMultinodeServer(t *testing.T, shows *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req := string(body)
+		switch {
+		case strings.Contains(req, "<methodName>scheduler.jobs.resubmit</methodName>"),
+			strings.Contains(req, "<methodName>scheduler.jobs.submit</methodName>"):
+			w.Write(xmlrpcResponse(`<value><array><data>` +
+				`<value><string>123.0</string></value>` +
+				`<value><string>123.1</string></value>` +
+				`</data></array></value>`))
+		case strings.Contains(req, "<methodName>scheduler.jobs.show</methodName>"):
+			*shows++
+			id := "123"
+			if strings.Contains(req, "<string>123.1</string>") {
+				id = "124"
+			}
+			w.Write(xmlrpcResponse(`<value><struct><member><name>id</name>` +
+				`<value><int>` + id + `</int></value></member></struct></value>`))
+		default:
+			t.Errorf("unexpected request %s", req)
+		}
+	}))
+}
+
+func TestConnection_JobsResubmitMultinode(t *testing.T) {
+	var shows int
+	srv := newMultinodeServer(t, &shows)
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := c.JobsResubmit(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 123 || ids[1] != 124 {
+		t.Errorf("JobsResubmit() = %v, want [123 124]", ids)
+	}
+	ids, err = c.JobsSubmitString("job_name: multinode\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 123 || ids[1] != 124 {
+		t.Errorf("JobsSubmitString() = %v, want [123 124]", ids)
+	}
+	if shows != 4 {
+		t.Errorf("scheduler.jobs.show called %d times, want 4", shows)
+	}
+}
+
+func TestJobNumbers(t *testing.T) {
+	tests := []struct {
+		xmlRet  interface{}
+		want    string
+		wantErr bool
+	}{
+		{int64(42), "42", false},
+		{[]interface{}{int64(1), int64(2)}, "1 2", false},
+		{[]interface{}{"123.0", "123.1"}, "123.0 123.1", false},
+		{"123.x", "", true},
+		{3.5, "", true},
+	}
+	for _, tt := range tests {
+		got, err := jobNumbers(tt.xmlRet)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("jobNumbers(%v) error = %v, wantErr %v", tt.xmlRet, err, tt.wantErr)
+			continue
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("jobNumbers(%v) = %v, want %s", tt.xmlRet, got, tt.want)
+		}
+	}
+}
+
+func TestConnection_JobsQueueMultinode(t *testing.T) {
+	job := func(id string) string {
+		return `<value><struct><member><name>id</name><value>` + id + `</value></member>` +
+			`<member><name>requested_device_type</name><value><string>qemu</string></value></member></struct></value>`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(xmlrpcResponse(`<value><array><data>` +
+			job(`<string>123.0</string>`) + job(`<string>123.1</string>`) + job(`<int>125</int>`) +
+			`</data></array></value>`))
+	}))
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := c.JobsQueue(nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id    int
+		subID string
+	}{{123, "123.0"}, {123, "123.1"}, {125, ""}}
+	if len(queue) != len(want) {
+		t.Fatalf("JobsQueue() = %+v, want %d jobs", queue, len(want))
+	}
+	for i, w := range want {
+		if queue[i].ID != w.id || queue[i].SubID != w.subID || queue[i].RequestedDeviceType != "qemu" {
+			t.Errorf("JobsQueue()[%d] = %+v, want ID %d, SubID %q", i, queue[i], w.id, w.subID)
+		}
+	}
+}
+
+// pagedJobs returns a list function serving n jobs, newest first, recording
+// the requested offsets. Requests at offset fail with err.
+func pagedJobs(n int, offsets *[]int, failAt int, err error) func(offset int, limit int) ([]JobsListing, error) {
+	return func(offset int, limit int) ([]JobsListing, error) {
+		*offsets = append(*offsets, offset)
+		if offset == failAt {
+			return nil, err
+		}
+		ret := []JobsListing{}
+		for id := n - offset; id > 0 && len(ret) < limit; id-- {
+			ret = append(ret, JobsListing{ID: id})
+		}
+		return ret, nil
+	}
+}
+
+func TestJobsIterator(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name    string
+		jobs    int
+		start   int
+		failAt  int
+		want    []int
+		offsets []int
+		wantErr error
+	}{
+		{"short last page", 5, 0, -1, []int{5, 4, 3, 2, 1}, []int{0, 2, 4}, nil},
+		{"full last page", 4, 0, -1, []int{4, 3, 2, 1}, []int{0, 2, 4}, nil},
+		{"empty", 0, 0, -1, nil, []int{0}, nil},
+		{"start", 5, 3, -1, []int{2, 1}, []int{3, 5}, nil},
+		{"error", 5, 0, 2, []int{5, 4}, []int{0, 2}, errFailed},
+	}
+	for _, tt := range tests {
+		var offsets []int
+		it := newJobsIterator(tt.start, 2, pagedJobs(tt.jobs, &offsets, tt.failAt, errFailed))
+		var got []int
+		for it.Next() {
+			got = append(got, it.Job().ID)
+		}
+		// Once done, no further pages are requested
+		if it.Next() {
+			t.Errorf("%s: Next() = true after the end", tt.name)
+		}
+		if it.Err() != tt.wantErr {
+			t.Errorf("%s: Err() = %v, want %v", tt.name, it.Err(), tt.wantErr)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || fmt.Sprint(offsets) != fmt.Sprint(tt.offsets) {
+			t.Errorf("%s: jobs %v, offsets %v, want jobs %v, offsets %v", tt.name, got, offsets, tt.want, tt.offsets)
+		}
+	}
+}
+
+func TestConnection_xmlrpcJobsListFilteredSince(t *testing.T) {
+	verbose := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var values string
+		// A single page of jobs, submitted hourly
+		for id := 5; id > 0; id-- {
+			values += fmt.Sprintf(`<value><struct>`+
+				`<member><name>id</name><value><int>%d</int></value></member>`, id)
+			if verbose && strings.Contains(string(body), "<boolean>1</boolean>") {
+				values += fmt.Sprintf(`<member><name>submit_time</name>`+
+					`<value><dateTime.iso8601>20201224T%02d:00:00</dateTime.iso8601></value></member>`, 10+id)
+			}
+			values += `</struct></value>`
+		}
+		w.Write(xmlrpcResponse(`<value><array><data>` + values + `</data></array></value>`))
+	}))
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := JobsFilter{SubmittedAfter: time.Date(2020, 12, 24, 13, 0, 0, 0, time.UTC),
+		SubmittedBefore: time.Date(2020, 12, 24, 15, 0, 0, 0, time.UTC)}
+	list, err := c.JobsListFiltered(filter, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != 4 || list[1].ID != 3 {
+		t.Errorf("JobsListFiltered() = %+v, want jobs 4 and 3", list)
+	}
+
+	// Servers without the verbose listing return a zero submit time
+	verbose = false
+	list, err = c.JobsListFiltered(filter, 0, 0)
+	if err == nil {
+		t.Errorf("JobsListFiltered() = %+v, expected error for missing submit times", list)
+	}
+}
@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) deviceType(name string) (*deviceType, error) {
+	dt, ok := f.deviceTypes[name]
+	if !ok {
+		return nil, notFound("DeviceType '%s' was not found.", name)
+	}
+	return dt, nil
+}
+
+// devicesOfType returns the sorted hostnames of all devices of the device type
+func (f *Fake) devicesOfType(name string) []string {
+	ret := []string{}
+	for _, d := range f.devices {
+		if d.DeviceType == name {
+			ret = append(ret, d.Hostname)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (f *Fake) DevicesTypesList(showAll bool) ([]lava.DeviceTypesListing, error) {
+	return f.DevicesTypesListContext(context.Background(), showAll)
+}
+
+// DevicesTypesListContext returns the device types. Unless showAll is set,
+// device types that are not displayed are skipped.
+func (f *Fake) DevicesTypesListContext(ctx context.Context, showAll bool) ([]lava.DeviceTypesListing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesList"); err != nil {
+		return nil, err
+	}
+
+	ret := []lava.DeviceTypesListing{}
+	for _, dt := range f.deviceTypes {
+		if !showAll && !dt.Display {
+			continue
+		}
+		ret = append(ret, lava.DeviceTypesListing{Name: dt.Name,
+			Devices:   len(f.devicesOfType(dt.Name)),
+			Installed: true,
+			Template:  dt.template != "",
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+
+	return ret, nil
+}
+
+func (f *Fake) DevicesTypesTemplateSet(name string, template string) error {
+	return f.DevicesTypesTemplateSetContext(context.Background(), name, template)
+}
+
+func (f *Fake) DevicesTypesTemplateSetContext(ctx context.Context, name string, template string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesTemplateSet"); err != nil {
+		return err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return err
+	}
+	dt.template = template
+
+	return nil
+}
+
+func (f *Fake) DevicesTypesTemplateGet(name string) (string, error) {
+	return f.DevicesTypesTemplateGetContext(context.Background(), name)
+}
+
+func (f *Fake) DevicesTypesTemplateGetContext(ctx context.Context, name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesTemplateGet"); err != nil {
+		return "", err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return "", err
+	}
+	if dt.template == "" {
+		return "", notFound("Device-type '%s' was not found.", name)
+	}
+
+	return dt.template, nil
+}
+
+func (f *Fake) DevicesTypesHealthCheckSet(name string, template string) error {
+	return f.DevicesTypesHealthCheckSetContext(context.Background(), name, template)
+}
+
+func (f *Fake) DevicesTypesHealthCheckSetContext(ctx context.Context, name string, template string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesHealthCheckSet"); err != nil {
+		return err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return err
+	}
+	dt.healthCheck = template
+
+	return nil
+}
+
+func (f *Fake) DevicesTypesHealthCheckGet(name string) (string, error) {
+	return f.DevicesTypesHealthCheckGetContext(context.Background(), name)
+}
+
+func (f *Fake) DevicesTypesHealthCheckGetContext(ctx context.Context, name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesHealthCheckGet"); err != nil {
+		return "", err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return "", err
+	}
+	if dt.healthCheck == "" {
+		return "", notFound("Device-type '%s' health check was not found.", name)
+	}
+
+	return dt.healthCheck, nil
+}
+
+func (f *Fake) DevicesTypesShow(name string) (*lava.DeviceType, error) {
+	return f.DevicesTypesShowContext(context.Background(), name)
+}
+
+func (f *Fake) DevicesTypesShowContext(ctx context.Context, name string) (*lava.DeviceType, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesShow"); err != nil {
+		return nil, err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return nil, err
+	}
+	ret := dt.DeviceType
+	ret.Aliases = append([]string{}, dt.Aliases...)
+	ret.Devices = f.devicesOfType(name)
+	ret.DefaultTemplate = dt.template == ""
+
+	return &ret, nil
+}
+
+func (f *Fake) DevicesTypesAdd(name string, s lava.DeviceTypeSettings) error {
+	return f.DevicesTypesAddContext(context.Background(), name, s)
+}
+
+func (f *Fake) DevicesTypesAddContext(ctx context.Context, name string, s lava.DeviceTypeSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesAdd"); err != nil {
+		return err
+	}
+
+	if _, ok := f.deviceTypes[name]; ok {
+		return invalidRequest("Device-type '%s' already exists", name)
+	}
+	dt := &deviceType{DeviceType: lava.DeviceType{Name: name,
+		Display:           true,
+		HealthFrequency:   24,
+		HealthDenominator: "hours",
+		Aliases:           []string{},
+	}}
+	updateDeviceType(dt, s)
+	f.deviceTypes[name] = dt
+
+	return nil
+}
+
+func (f *Fake) DevicesTypesUpdate(name string, s lava.DeviceTypeSettings) error {
+	return f.DevicesTypesUpdateContext(context.Background(), name, s)
+}
+
+func (f *Fake) DevicesTypesUpdateContext(ctx context.Context, name string, s lava.DeviceTypeSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesUpdate"); err != nil {
+		return err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return err
+	}
+	updateDeviceType(dt, s)
+
+	return nil
+}
+
+func updateDeviceType(dt *deviceType, s lava.DeviceTypeSettings) {
+	if s.Description != "" {
+		dt.Description = s.Description
+	}
+	if s.Display != nil {
+		dt.Display = *s.Display
+	}
+	if s.OwnersOnly != nil {
+		dt.OwnersOnly = *s.OwnersOnly
+	}
+	if s.HealthFrequency != nil {
+		dt.HealthFrequency = *s.HealthFrequency
+	}
+	if s.HealthDenominator != "" {
+		dt.HealthDenominator = s.HealthDenominator
+	}
+	if s.HealthDisabled != nil {
+		dt.HealthDisabled = *s.HealthDisabled
+	}
+}
+
+func (f *Fake) DevicesTypesAliasesList(name string) ([]string, error) {
+	return f.DevicesTypesAliasesListContext(context.Background(), name)
+}
+
+func (f *Fake) DevicesTypesAliasesListContext(ctx context.Context, name string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesAliasesList"); err != nil {
+		return nil, err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{}, dt.Aliases...), nil
+}
+
+func (f *Fake) DevicesTypesAliasesAdd(name string, alias string) error {
+	return f.DevicesTypesAliasesAddContext(context.Background(), name, alias)
+}
+
+func (f *Fake) DevicesTypesAliasesAddContext(ctx context.Context, name string, alias string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesAliasesAdd"); err != nil {
+		return err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return err
+	}
+	for _, other := range f.deviceTypes {
+		if contains(other.Aliases, alias) {
+			return invalidRequest("Alias '%s' already exists", alias)
+		}
+	}
+	dt.Aliases = append(dt.Aliases, alias)
+
+	return nil
+}
+
+func (f *Fake) DevicesTypesAliasesDelete(name string, alias string) error {
+	return f.DevicesTypesAliasesDeleteContext(context.Background(), name, alias)
+}
+
+func (f *Fake) DevicesTypesAliasesDeleteContext(ctx context.Context, name string, alias string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTypesAliasesDelete"); err != nil {
+		return err
+	}
+
+	dt, err := f.deviceType(name)
+	if err != nil {
+		return err
+	}
+	if !contains(dt.Aliases, alias) {
+		return notFound("Alias '%s' was not found.", alias)
+	}
+	dt.Aliases = remove(dt.Aliases, alias)
+
+	return nil
+}
@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) device(hostname string) (*device, error) {
+	d, ok := f.devices[hostname]
+	if !ok {
+		return nil, notFound("Device '%s' was not found.", hostname)
+	}
+	return d, nil
+}
+
+func (f *Fake) DevicesList() ([]lava.DeviceList, error) {
+	return f.DevicesListContext(context.Background())
+}
+
+func (f *Fake) DevicesListContext(ctx context.Context) ([]lava.DeviceList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesList"); err != nil {
+		return nil, err
+	}
+
+	return f.devicesList(lava.DevicesFilter{}), nil
+}
+
+// devicesList returns the devices matching the filter, sorted by hostname
+func (f *Fake) devicesList(filter lava.DevicesFilter) []lava.DeviceList {
+	ret := []lava.DeviceList{}
+	for _, d := range f.devices {
+		dev := lava.DeviceList{Hostname: d.Hostname,
+			Type:       d.DeviceType,
+			State:      d.State,
+			Health:     d.Health,
+			CurrentJob: d.CurrentJob,
+			Pipeline:   d.Pipeline,
+		}
+		if filter.Match(dev) {
+			ret = append(ret, dev)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Hostname < ret[j].Hostname })
+
+	return ret
+}
+
+func (f *Fake) DevicesListFiltered(filter lava.DevicesFilter) ([]lava.DeviceList, error) {
+	return f.DevicesListFilteredContext(context.Background(), filter)
+}
+
+func (f *Fake) DevicesListFilteredContext(ctx context.Context, filter lava.DevicesFilter) ([]lava.DeviceList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesListFiltered"); err != nil {
+		return nil, err
+	}
+
+	return f.devicesList(filter), nil
+}
+
+func (f *Fake) DevicesShow(hostname string) (*lava.Device, error) {
+	return f.DevicesShowContext(context.Background(), hostname)
+}
+
+func (f *Fake) DevicesShowContext(ctx context.Context, hostname string) (*lava.Device, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesShow"); err != nil {
+		return nil, err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return nil, err
+	}
+	ret := d.Device
+	ret.Tags = append([]string{}, d.Tags...)
+	ret.HasDeviceDict = d.dict != ""
+
+	return &ret, nil
+}
+
+func (f *Fake) DevicesTagsList(hostname string) ([]string, error) {
+	return f.DevicesTagsListContext(context.Background(), hostname)
+}
+
+func (f *Fake) DevicesTagsListContext(ctx context.Context, hostname string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTagsList"); err != nil {
+		return nil, err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{}, d.Tags...), nil
+}
+
+func (f *Fake) DevicesTagsDelete(hostname string, name string) error {
+	return f.DevicesTagsDeleteContext(context.Background(), hostname, name)
+}
+
+func (f *Fake) DevicesTagsDeleteContext(ctx context.Context, hostname string, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTagsDelete"); err != nil {
+		return err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.tags[name]; !ok {
+		return notFound("Tag '%s' was not found.", name)
+	}
+	d.Tags = remove(d.Tags, name)
+
+	return nil
+}
+
+func (f *Fake) DevicesTagsAdd(hostname string, name string) error {
+	return f.DevicesTagsAddContext(context.Background(), hostname, name)
+}
+
+// DevicesTagsAddContext adds the tag to the device. Like the server, it
+// creates unknown tags.
+func (f *Fake) DevicesTagsAddContext(ctx context.Context, hostname string, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesTagsAdd"); err != nil {
+		return err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.tags[name]; !ok {
+		f.tags[name] = ""
+	}
+	if !contains(d.Tags, name) {
+		d.Tags = append(d.Tags, name)
+	}
+
+	return nil
+}
+
+func (f *Fake) DevicesAdd(hostname string, s lava.DeviceSettings) error {
+	return f.DevicesAddContext(context.Background(), hostname, s)
+}
+
+func (f *Fake) DevicesAddContext(ctx context.Context, hostname string, s lava.DeviceSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesAdd"); err != nil {
+		return err
+	}
+
+	if s.DeviceType == "" || s.Worker == "" {
+		return fmt.Errorf("Must specify device type and worker")
+	}
+	if _, ok := f.devices[hostname]; ok {
+		return invalidRequest("Device '%s' already exists.", hostname)
+	}
+	if _, ok := f.deviceTypes[s.DeviceType]; !ok {
+		return notFound("DeviceType '%s' was not found.", s.DeviceType)
+	}
+	if _, ok := f.workers[s.Worker]; !ok {
+		return notFound("Worker '%s' was not found.", s.Worker)
+	}
+	health := "Unknown"
+	if s.Health != "" {
+		health = title(s.Health)
+	}
+
+	f.devices[hostname] = &device{Device: lava.Device{Hostname: hostname,
+		Description: s.Description,
+		DeviceType:  s.DeviceType,
+		Worker:      s.Worker,
+		State:       "Idle",
+		Health:      health,
+		Pipeline:    true,
+		Tags:        []string{},
+	}}
+	f.publishDevice(f.devices[hostname])
+
+	return nil
+}
+
+func (f *Fake) DevicesUpdate(hostname string, s lava.DeviceSettings) error {
+	return f.DevicesUpdateContext(context.Background(), hostname, s)
+}
+
+func (f *Fake) DevicesUpdateContext(ctx context.Context, hostname string, s lava.DeviceSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesUpdate"); err != nil {
+		return err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	if s.DeviceType != "" {
+		if _, ok := f.deviceTypes[s.DeviceType]; !ok {
+			return notFound("DeviceType '%s' was not found.", s.DeviceType)
+		}
+		d.DeviceType = s.DeviceType
+	}
+	if s.Worker != "" {
+		if _, ok := f.workers[s.Worker]; !ok {
+			return notFound("Worker '%s' was not found.", s.Worker)
+		}
+		d.Worker = s.Worker
+	}
+	if s.Description != "" {
+		d.Description = s.Description
+	}
+	if s.Health != "" && title(s.Health) != d.Health {
+		d.Health = title(s.Health)
+		f.publishDevice(d)
+	}
+
+	return nil
+}
+
+func (f *Fake) DevicesDictionaryGet(hostname string, render bool) (string, error) {
+	return f.DevicesDictionaryGetContext(context.Background(), hostname, render)
+}
+
+func (f *Fake) DevicesDictionaryGetContext(ctx context.Context, hostname string, render bool) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesDictionaryGet"); err != nil {
+		return "", err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return "", err
+	}
+	if d.dict == "" {
+		return "", notFound("Device '%s' does not have a configuration", hostname)
+	}
+
+	return d.dict, nil
+}
+
+func (f *Fake) DevicesDictionarySet(hostname string, dict string) error {
+	return f.DevicesDictionarySetContext(context.Background(), hostname, dict)
+}
+
+func (f *Fake) DevicesDictionarySetContext(ctx context.Context, hostname string, dict string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DevicesDictionarySet"); err != nil {
+		return err
+	}
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	d.dict = dict
+
+	return nil
+}
+
+// SetDeviceState sets the state of the device, like "Idle", and publishes a
+// device event
+func (f *Fake) SetDeviceState(hostname string, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	d.State = title(state)
+	f.publishDevice(d)
+
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []string, s string) []string {
+	ret := []string{}
+	for _, e := range list {
+		if e != s {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
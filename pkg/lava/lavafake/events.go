@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+// eventBuffer is the number of events queued per listener. Further events
+// are dropped until the listener catches up.
+const eventBuffer = 100
+
+// eventsURI is the URI returned by EventsURI
+const eventsURI = "ws://lavafake/ws/"
+
+// Publish sends the event to all listeners. Topic, UUID, DateTime, Username
+// and Data are filled in unless set. The fake publishes testjob, device and
+// worker events on its own when their state changes.
+func (f *Fake) Publish(ev *lava.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.publish(ev)
+}
+
+func (f *Fake) publish(ev *lava.Event) {
+	if ev.Topic == "" {
+		ev.Topic = "org.lavasoftware." + ev.Type
+	}
+	if ev.UUID == "" {
+		f.published++
+		ev.UUID = fmt.Sprintf("00000000-0000-0000-0000-%012d", f.published)
+	}
+	if ev.DateTime == "" {
+		ev.DateTime = now().Format("2006-01-02T15:04:05.000000")
+	}
+	if ev.Username == "" {
+		ev.Username = f.username
+	}
+	if ev.Data == nil {
+		switch {
+		case ev.TestJob != nil:
+			ev.Data, _ = json.Marshal(ev.TestJob)
+		case ev.Device != nil:
+			ev.Data, _ = json.Marshal(ev.Device)
+		case ev.Worker != nil:
+			ev.Data, _ = json.Marshal(ev.Worker)
+		case ev.Job != nil:
+			ev.Data, _ = json.Marshal(ev.Job)
+		}
+	}
+
+	for l := range f.listeners {
+		e := *ev
+		select {
+		case l <- &e:
+		default:
+		}
+	}
+}
+
+func (f *Fake) publishJob(j *job) {
+	ev := lava.TestJobEvent{Job: json.Number(strconv.Itoa(j.ID)),
+		Description: j.Description,
+		DeviceType:  j.DeviceType,
+		Device:      j.Device,
+		State:       j.State,
+		Health:      j.Health,
+		Submitter:   j.Submitter,
+		HealthCheck: j.HealthCheck,
+		Visibility:  j.Visibility,
+		SubmitTime:  j.SubmitTime.Format("2006-01-02T15:04:05.000000Z"),
+	}
+	if !j.StartTime.IsZero() {
+		ev.StartTime = j.StartTime.Format("2006-01-02T15:04:05.000000Z")
+	}
+	if !j.EndTime.IsZero() {
+		ev.EndTime = j.EndTime.Format("2006-01-02T15:04:05.000000Z")
+	}
+	f.publish(&lava.Event{Type: lava.EventTypeTestJob, TestJob: &ev})
+}
+
+func (f *Fake) publishDevice(d *device) {
+	ev := lava.DeviceEvent{Device: d.Hostname,
+		DeviceType: d.DeviceType,
+		State:      d.State,
+		Health:     d.Health,
+	}
+	if d.CurrentJob != 0 {
+		ev.Job = json.Number(strconv.Itoa(d.CurrentJob))
+	}
+	f.publish(&lava.Event{Type: lava.EventTypeDevice, Device: &ev})
+}
+
+func (f *Fake) publishWorker(w *worker) {
+	f.publish(&lava.Event{Type: lava.EventTypeWorker,
+		Worker: &lava.WorkerEvent{Worker: w.Hostname, State: w.State, Health: w.Health},
+	})
+}
+
+// EventsURI returns a fixed URI, the fake doesn't listen on it
+func (f *Fake) EventsURI() (string, error) {
+	return eventsURI, nil
+}
+
+func (f *Fake) EventsListen() (*lava.EventListener, error) {
+	return f.EventsListenContext(context.Background())
+}
+
+// EventsListenContext returns a listener receiving the events published
+// after the call, until ctx is done
+func (f *Fake) EventsListenContext(ctx context.Context) (*lava.EventListener, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "EventsListen"); err != nil {
+		return nil, err
+	}
+
+	return f.listen(ctx), nil
+}
+
+func (f *Fake) EventsListenURI(uri string) (*lava.EventListener, error) {
+	return f.EventsListenURIContext(context.Background(), uri)
+}
+
+// EventsListenURIContext is like EventsListenContext, the uri is ignored
+func (f *Fake) EventsListenURIContext(ctx context.Context, uri string) (*lava.EventListener, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "EventsListenURI"); err != nil {
+		return nil, err
+	}
+
+	return f.listen(ctx), nil
+}
+
+func (f *Fake) listen(ctx context.Context) *lava.EventListener {
+	events := make(chan *lava.Event, eventBuffer)
+	f.listeners[events] = struct{}{}
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		delete(f.listeners, events)
+		f.mu.Unlock()
+	}()
+
+	return lava.NewEventListener(ctx, events)
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package lavafake provides an in-memory implementation of lava.Client to
+// test code using the LAVA API without a server.
+//
+// The fake starts empty. Populate it using the regular API, for example
+// DevicesTypesAdd, WorkersAdd, DevicesAdd and JobsSubmitString, and drive
+// jobs through their life cycle with StartJob and SetJobState:
+//
+//	f := lavafake.New()
+//	f.DevicesTypesAdd("qemu", lava.DeviceTypeSettings{})
+//	f.WorkersAdd("worker01", "", false)
+//	f.DevicesAdd("qemu01", lava.DeviceSettings{DeviceType: "qemu", Worker: "worker01"})
+//	ids, _ := f.JobsSubmitString(definition)
+//	f.StartJob(ids[0], "qemu01")
+//	f.SetJobState(ids[0], "Finished", "Complete")
+//
+// Errors returned by the fake are the typed errors of package lava, so
+// errors.Is(err, lava.ErrNotFound) works as with a real server. Failures can
+// be injected per method using SetError.
+package lavafake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+type device struct {
+	lava.Device
+	dict string
+}
+
+type deviceType struct {
+	lava.DeviceType
+	template    string
+	healthCheck string
+}
+
+type job struct {
+	lava.JobState
+	definition string
+	priority   int
+	logs       string
+	results    lava.Result
+}
+
+type worker struct {
+	lava.WorkerDetail
+	config string
+	env    string
+}
+
+type group struct {
+	lava.GroupDetail
+	perms []lava.Permission
+}
+
+// Fake is an in-memory LAVA server implementing lava.Client.
+// It's safe for concurrent use.
+type Fake struct {
+	mu          sync.Mutex
+	devices     map[string]*device
+	deviceTypes map[string]*deviceType
+	jobs        map[int]*job
+	lastJobID   int
+	workers     map[string]*worker
+	tags        map[string]string
+	users       map[string]*lava.UserDetail
+	groups      map[string]*group
+	version     string
+	apiVersion  int
+	username    string
+	errors      map[string]error
+	calls       map[string]int
+	listeners   map[chan *lava.Event]struct{}
+	published   int
+}
+
+var _ lava.Client = (*Fake)(nil)
+
+// New returns an empty fake server. The requests are made by the user "lavafake".
+func New() *Fake {
+	return &Fake{devices: map[string]*device{},
+		deviceTypes: map[string]*deviceType{},
+		jobs:        map[int]*job{},
+		workers:     map[string]*worker{},
+		tags:        map[string]string{},
+		users:       map[string]*lava.UserDetail{},
+		groups:      map[string]*group{},
+		version:     "2020.08",
+		apiVersion:  2,
+		username:    "lavafake",
+		errors:      map[string]error{},
+		calls:       map[string]int{},
+		listeners:   map[chan *lava.Event]struct{}{},
+	}
+}
+
+// SetError makes all calls of method fail with err, until cleared by passing
+// a nil error. Method is the name of the lava.Client method without the
+// Context suffix, like "JobsShow".
+func (f *Fake) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errors, method)
+		return
+	}
+	f.errors[method] = err
+}
+
+// Calls returns how often method has been called. Method is the name of the
+// lava.Client method without the Context suffix, like "JobsShow".
+func (f *Fake) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+// SetUser sets the name of the user making the requests
+func (f *Fake) SetUser(username string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.username = username
+}
+
+// SetVersion sets the version and API version reported by the server
+func (f *Fake) SetVersion(version string, apiVersion int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.version = version
+	f.apiVersion = apiVersion
+}
+
+// call records the call of method and returns the error to fail it with.
+// Must be called with f.mu held.
+func (f *Fake) call(ctx context.Context, method string) error {
+	f.calls[method]++
+	if err := ctx.Err(); err != nil {
+		return &lava.TransportError{Err: err}
+	}
+	return f.errors[method]
+}
+
+func notFound(format string, a ...interface{}) error {
+	return &lava.NotFoundError{Fault: lava.Fault{Code: 404, Message: fmt.Sprintf(format, a...)}}
+}
+
+func invalidRequest(format string, a ...interface{}) error {
+	return &lava.InvalidRequestError{Fault: lava.Fault{Code: 400, Message: fmt.Sprintf(format, a...)}}
+}
+
+// title normalizes states and healths, the server accepts any case but
+// always returns them capitalized
+func title(s string) string {
+	return strings.Title(strings.ToLower(s))
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,251 @@
+package lavafake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+const definition = `
+device_type: qemu
+job_name: smoke test
+priority: medium
+visibility: public
+actions: []
+`
+
+func newFake(t *testing.T) *Fake {
+	f := New()
+	if err := f.DevicesTypesAdd("qemu", lava.DeviceTypeSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WorkersAdd("worker01", "", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.DevicesAdd("qemu01", lava.DeviceSettings{DeviceType: "qemu", Worker: "worker01", Health: "GOOD"}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFake_jobLifecycle(t *testing.T) {
+	f := newFake(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l, err := f.EventsListenContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := f.JobsSubmitString(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("JobsSubmitString() = %v, want [1]", ids)
+	}
+	if err := f.StartJob(1, "qemu01"); err != nil {
+		t.Fatal(err)
+	}
+	dev, err := f.DevicesShow("qemu01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.CurrentJob != 1 || dev.State != "Running" || dev.Health != "Good" {
+		t.Errorf("DevicesShow() = %+v, want device running job 1", dev)
+	}
+
+	if err := f.SetJobState(1, "finished", "complete"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := f.JobsShow(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != "Finished" || job.Health != "Complete" || job.Device != "qemu01" || job.Description != "smoke test" {
+		t.Errorf("JobsShow() = %+v, want finished job on qemu01", job)
+	}
+	dev, _ = f.DevicesShow("qemu01")
+	if dev.CurrentJob != 0 || dev.State != "Idle" {
+		t.Errorf("DevicesShow() = %+v, want idle device", dev)
+	}
+
+	var states []string
+	for len(states) < 3 {
+		ev, err := l.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type == lava.EventTypeTestJob && ev.JobID() == 1 {
+			states = append(states, ev.TestJob.State)
+		}
+	}
+	if states[0] != "Submitted" || states[1] != "Running" || states[2] != "Finished" {
+		t.Errorf("testjob events = %v, want [Submitted Running Finished]", states)
+	}
+
+	cancel()
+	if _, err := l.Next(); err != context.Canceled {
+		t.Errorf("Next() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFake_errors(t *testing.T) {
+	f := newFake(t)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"job", func() error { _, err := f.JobsShow(42); return err }(), lava.ErrNotFound},
+		{"device", f.DevicesUpdate("missing", lava.DeviceSettings{}), lava.ErrNotFound},
+		{"duplicate worker", f.WorkersAdd("worker01", "", false), lava.ErrInvalidRequest},
+		{"unknown device type", func() error { _, err := f.JobsSubmitString("device_type: x86\njob_name: x\n"); return err }(), lava.ErrInvalidRequest},
+		{"invalid definition", func() error { _, err := f.JobsSubmitString("job_name: x\n"); return err }(), lava.ErrInvalidRequest},
+		{"unknown method", func() error { _, err := f.SystemMethodHelp("system.reboot"); return err }(), lava.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.DevicesListContext(ctx); !errors.Is(err, lava.ErrTransport) || !errors.Is(err, context.Canceled) {
+		t.Errorf("DevicesListContext() error = %v, want TransportError wrapping context.Canceled", err)
+	}
+}
+
+func TestFake_SetError(t *testing.T) {
+	f := newFake(t)
+
+	injected := &lava.ServerError{Fault: lava.Fault{Code: 500, Message: "Internal error"}}
+	f.SetError("DevicesList", injected)
+	if _, err := f.DevicesList(); err != injected {
+		t.Errorf("DevicesList() error = %v, want %v", err, injected)
+	}
+	if _, err := f.DevicesListContext(context.Background()); err != injected {
+		t.Errorf("DevicesListContext() error = %v, want %v", err, injected)
+	}
+	f.SetError("DevicesList", nil)
+	list, err := f.DevicesList()
+	if err != nil || len(list) != 1 || list[0].Hostname != "qemu01" {
+		t.Errorf("DevicesList() = %v, %v, want [qemu01]", list, err)
+	}
+	if n := f.Calls("DevicesList"); n != 3 {
+		t.Errorf("Calls() = %d, want 3", n)
+	}
+}
+
+func TestFake_jobsList(t *testing.T) {
+	f := newFake(t)
+	for i := 0; i < 5; i++ {
+		if _, err := f.JobsSubmitString(definition); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.JobsCancel(2)
+	f.JobsFail(3)
+
+	list, err := f.JobsList("SUBMITTED", "", 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != 5 || list[1].ID != 4 {
+		t.Errorf("JobsList() = %v, want jobs 5 and 4", list)
+	}
+	list, _ = f.JobsList("", "canceled", 0, 0)
+	if len(list) != 1 || list[0].ID != 2 {
+		t.Errorf("JobsList() = %v, want job 2", list)
+	}
+
+	var ids []int
+	it := f.JobsIter("finished", "", 1)
+	for it.Next() {
+		ids = append(ids, it.Job().ID)
+	}
+	if it.Err() != nil || len(ids) != 2 || ids[0] != 3 || ids[1] != 2 {
+		t.Errorf("JobsIter() = %v, %v, want jobs 3 and 2", ids, it.Err())
+	}
+	// Two full pages and an empty one
+	if n := f.Calls("JobsList"); n != 5 {
+		t.Errorf("JobsList called %d times, want 5", n)
+	}
+
+	f.SetError("JobsList", &lava.ServerError{Fault: lava.Fault{Code: 500, Message: "Internal error"}})
+	it = f.JobsIter("", "", 2)
+	if it.Next() || !errors.Is(it.Err(), lava.ErrServer) {
+		t.Errorf("JobsIter() error = %v, want ErrServer", it.Err())
+	}
+	f.SetError("JobsList", nil)
+
+	queue, err := f.JobsQueue([]string{"qemu"}, 0, 0)
+	if err != nil || len(queue) != 3 || queue[0].ID != 1 {
+		t.Errorf("JobsQueue() = %v, %v, want jobs 1, 4 and 5", queue, err)
+	}
+}
+
+func TestFake_logsAndResults(t *testing.T) {
+	f := newFake(t)
+	f.JobsSubmitString(definition)
+	f.StartJob(1, "qemu01")
+	f.AppendJobLog(1, "info", "start: 1 deploy")
+	f.AppendJobLog(1, "target", "login: \"root\"")
+	f.AppendJobLog(1, "info", "end: 1 deploy")
+
+	logs, err := f.JobsLogsRange(1, 1, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs.Finished || logs.Lines != 2 || len(logs.Decoded) != 2 || logs.Decoded[0].Message != "login: \"root\"" {
+		t.Errorf("JobsLogsRange() = %+v, want the last two lines", logs)
+	}
+
+	f.SetJobResults(1, lava.Result{
+		{Name: "boot", Suite: "lava", Result: "pass"},
+		{Name: "ping", Suite: "0_smoke", Result: "fail"},
+	})
+	res, err := f.ResultsSuite(1, "0_smoke")
+	if err != nil || len(res) != 1 || res[0].Name != "ping" || res[0].Job != "1" {
+		t.Errorf("ResultsSuite() = %v, %v, want ping", res, err)
+	}
+	if _, err := f.ResultsCase(1, "lava", "ping"); !errors.Is(err, lava.ErrNotFound) {
+		t.Errorf("ResultsCase() error = %v, want ErrNotFound", err)
+	}
+	csv, err := f.ResultsAsCSV(1)
+	if err != nil || csv != "job,suite,result,measurement,duration,logged,level,url,name,id,log_start_line,log_end_line\n"+
+		"1,lava,pass,,,,,,boot,,,\n1,0_smoke,fail,,,,,,ping,,,\n" {
+		t.Errorf("ResultsAsCSV() = %q, %v", csv, err)
+	}
+
+	f.SetJobState(1, "Finished", "Incomplete")
+	logs, _ = f.JobsLogs(1, true)
+	if !logs.Finished || logs.Lines != 3 {
+		t.Errorf("JobsLogs() = %+v, want all lines of a finished job", logs)
+	}
+}
+
+func TestFake_Publish(t *testing.T) {
+	f := New()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	l, err := f.EventsListenURIContext(ctx, "ws://ignored/ws/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Publish(&lava.Event{Type: lava.EventTypeJob, Job: &lava.JobEvent{Job: "7", Message: "hello"}})
+
+	ev, err := l.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Topic != "org.lavasoftware.event" || ev.Username != "lavafake" || ev.JobID() != 7 || string(ev.Data) != `{"job":7,"message":"hello"}` {
+		t.Errorf("Next() = %+v", ev)
+	}
+}
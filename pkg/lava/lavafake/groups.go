@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) group(name string) (*group, error) {
+	g, ok := f.groups[name]
+	if !ok {
+		return nil, notFound("Group '%s' was not found.", name)
+	}
+	return g, nil
+}
+
+// permissions returns the permissions of the group as "app.codename"
+func (g *group) permissions() []string {
+	ret := []string{}
+	for _, p := range g.perms {
+		ret = append(ret, p.App+"."+p.Codename)
+	}
+	return ret
+}
+
+// AddGroup adds a group with the given members. The API doesn't provide a
+// method to add groups, so this is the only way to create them.
+func (f *Fake) AddGroup(name string, users ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.groups[name]; ok {
+		return invalidRequest("Group '%s' already exists", name)
+	}
+	for _, u := range users {
+		if _, err := f.user(u); err != nil {
+			return err
+		}
+	}
+	f.groups[name] = &group{GroupDetail: lava.GroupDetail{ID: len(f.groups) + 1,
+		Name:  name,
+		Users: append([]string{}, users...),
+	}}
+
+	return nil
+}
+
+func (f *Fake) GroupsList() ([]lava.Group, error) {
+	return f.GroupsListContext(context.Background())
+}
+
+func (f *Fake) GroupsListContext(ctx context.Context) ([]lava.Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GroupsList"); err != nil {
+		return nil, err
+	}
+
+	ret := []lava.Group{}
+	for _, g := range f.groups {
+		ret = append(ret, lava.Group{Name: g.Name})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+
+	return ret, nil
+}
+
+func (f *Fake) GroupsShow(name string) (*lava.GroupDetail, error) {
+	return f.GroupsShowContext(context.Background(), name)
+}
+
+func (f *Fake) GroupsShowContext(ctx context.Context, name string) (*lava.GroupDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GroupsShow"); err != nil {
+		return nil, err
+	}
+
+	g, err := f.group(name)
+	if err != nil {
+		return nil, err
+	}
+	ret := g.GroupDetail
+	ret.Users = append([]string{}, g.Users...)
+	sort.Strings(ret.Users)
+	ret.Permissions = g.permissions()
+
+	return &ret, nil
+}
+
+func (f *Fake) GroupsPermsList(name string) ([]lava.Permission, error) {
+	return f.GroupsPermsListContext(context.Background(), name)
+}
+
+func (f *Fake) GroupsPermsListContext(ctx context.Context, name string) ([]lava.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GroupsPermsList"); err != nil {
+		return nil, err
+	}
+
+	g, err := f.group(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]lava.Permission{}, g.perms...), nil
+}
+
+func (f *Fake) GroupsPermsAdd(name string, app string, codename string) error {
+	return f.GroupsPermsAddContext(context.Background(), name, app, codename)
+}
+
+func (f *Fake) GroupsPermsAddContext(ctx context.Context, name string, app string, codename string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GroupsPermsAdd"); err != nil {
+		return err
+	}
+
+	g, err := f.group(name)
+	if err != nil {
+		return err
+	}
+	for _, p := range g.perms {
+		if p.App == app && p.Codename == codename {
+			return nil
+		}
+	}
+	g.perms = append(g.perms, lava.Permission{App: app, Codename: codename, Name: codename})
+
+	return nil
+}
+
+func (f *Fake) GroupsPermsDelete(name string, app string, codename string) error {
+	return f.GroupsPermsDeleteContext(context.Background(), name, app, codename)
+}
+
+func (f *Fake) GroupsPermsDeleteContext(ctx context.Context, name string, app string, codename string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GroupsPermsDelete"); err != nil {
+		return err
+	}
+
+	g, err := f.group(name)
+	if err != nil {
+		return err
+	}
+	for i, p := range g.perms {
+		if p.App == app && p.Codename == codename {
+			g.perms = append(g.perms[:i], g.perms[i+1:]...)
+			return nil
+		}
+	}
+
+	return notFound("Permission '%s.%s' was not found.", app, codename)
+}
@@ -0,0 +1,587 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"gopkg.in/yaml.v2"
+)
+
+func (f *Fake) job(id int) (*job, error) {
+	j, ok := f.jobs[id]
+	if !ok {
+		return nil, notFound("Job '%d' was not found.", id)
+	}
+	return j, nil
+}
+
+// sortedJobs returns the jobs, newest first
+func (f *Fake) sortedJobs() []*job {
+	ret := make([]*job, 0, len(f.jobs))
+	for _, j := range f.jobs {
+		ret = append(ret, j)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID > ret[j].ID })
+	return ret
+}
+
+// page returns the bounds of the page of n entries starting at start.
+// A limit of zero returns all entries.
+func page(n int, start int, limit int) (int, int) {
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
+
+// priority converts the priority of a job definition as done by the server
+func priority(p string) int {
+	switch strings.ToLower(p) {
+	case "high":
+		return 100
+	case "low":
+		return 0
+	}
+	if n, err := strconv.Atoi(p); err == nil {
+		return n
+	}
+	return 50
+}
+
+func (f *Fake) JobsList(state string, health string, start int, limit int) ([]lava.JobsListing, error) {
+	return f.JobsListContext(context.Background(), state, health, start, limit)
+}
+
+// JobsListContext returns the jobs, newest first. State and health are
+// compared ignoring case, empty strings match all jobs.
+func (f *Fake) JobsListContext(ctx context.Context, state string, health string, start int, limit int) ([]lava.JobsListing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsList"); err != nil {
+		return nil, err
+	}
+
+	ret := f.jobsList(lava.JobsFilter{State: state, Health: health})
+	start, end := page(len(ret), start, limit)
+
+	return ret[start:end], nil
+}
+
+// jobsList returns the jobs matching the filter, newest first
+func (f *Fake) jobsList(filter lava.JobsFilter) []lava.JobsListing {
+	ret := []lava.JobsListing{}
+	for _, j := range f.sortedJobs() {
+		job := lava.JobsListing{ID: j.ID,
+			Description: j.Description,
+			DeviceType:  j.DeviceType,
+			Health:      j.Health,
+			State:       j.State,
+			Submitter:   j.Submitter,
+			SubmitTime:  j.SubmitTime,
+		}
+		if filter.Match(job) {
+			ret = append(ret, job)
+		}
+	}
+	return ret
+}
+
+func (f *Fake) JobsListFiltered(filter lava.JobsFilter, start int, limit int) ([]lava.JobsListing, error) {
+	return f.JobsListFilteredContext(context.Background(), filter, start, limit)
+}
+
+// JobsListFilteredContext returns the jobs matching the filter, newest first,
+// like a server supporting the REST API
+func (f *Fake) JobsListFilteredContext(ctx context.Context, filter lava.JobsFilter, start int, limit int) ([]lava.JobsListing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsListFiltered"); err != nil {
+		return nil, err
+	}
+
+	ret := f.jobsList(filter)
+	start, end := page(len(ret), start, limit)
+
+	return ret[start:end], nil
+}
+
+func (f *Fake) JobsIter(state string, health string, pageSize int) *lava.JobsIterator {
+	return f.JobsIterContext(context.Background(), state, health, pageSize)
+}
+
+func (f *Fake) JobsIterContext(ctx context.Context, state string, health string, pageSize int) *lava.JobsIterator {
+	return lava.NewJobsIterator(ctx, f, state, health, 0, pageSize)
+}
+
+func (f *Fake) JobsIterFrom(state string, health string, start int, pageSize int) *lava.JobsIterator {
+	return f.JobsIterFromContext(context.Background(), state, health, start, pageSize)
+}
+
+func (f *Fake) JobsIterFromContext(ctx context.Context, state string, health string, start int, pageSize int) *lava.JobsIterator {
+	return lava.NewJobsIterator(ctx, f, state, health, start, pageSize)
+}
+
+func (f *Fake) JobsQueue(deviceTypes []string, start int, limit int) ([]lava.JobsQueueListing, error) {
+	return f.JobsQueueContext(context.Background(), deviceTypes, start, limit)
+}
+
+// JobsQueueContext returns the submitted jobs of the device types, or of
+// all device types if none are given. Jobs with higher priority come first.
+func (f *Fake) JobsQueueContext(ctx context.Context, deviceTypes []string, start int, limit int) ([]lava.JobsQueueListing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsQueue"); err != nil {
+		return nil, err
+	}
+
+	var queued []*job
+	for _, j := range f.jobs {
+		if j.State != "Submitted" {
+			continue
+		}
+		if len(deviceTypes) > 0 && !contains(deviceTypes, j.DeviceType) {
+			continue
+		}
+		queued = append(queued, j)
+	}
+	sort.Slice(queued, func(a, b int) bool {
+		if queued[a].priority != queued[b].priority {
+			return queued[a].priority > queued[b].priority
+		}
+		return queued[a].ID < queued[b].ID
+	})
+
+	ret := []lava.JobsQueueListing{}
+	for _, j := range queued {
+		ret = append(ret, lava.JobsQueueListing{ID: j.ID,
+			Description:         j.Description,
+			RequestedDeviceType: j.DeviceType,
+			Submitter:           j.Submitter,
+			SubmitTime:          j.SubmitTime,
+			Priority:            j.priority,
+			Tags:                append([]string{}, j.Tags...),
+		})
+	}
+	start, end := page(len(ret), start, limit)
+
+	return ret[start:end], nil
+}
+
+func (f *Fake) JobsShow(id int) (*lava.JobState, error) {
+	return f.JobsShowContext(context.Background(), id)
+}
+
+func (f *Fake) JobsShowContext(ctx context.Context, id int) (*lava.JobState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsShow"); err != nil {
+		return nil, err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return nil, err
+	}
+	ret := j.JobState
+	ret.Tags = append([]string{}, j.Tags...)
+
+	return &ret, nil
+}
+
+func (f *Fake) JobsDefinition(id int) (lava.JobDefintion, error) {
+	return f.JobsDefinitionContext(context.Background(), id)
+}
+
+func (f *Fake) JobsDefinitionContext(ctx context.Context, id int) (lava.JobDefintion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsDefinition"); err != nil {
+		return "", err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return "", err
+	}
+
+	return lava.JobDefintion(j.definition), nil
+}
+
+// validate checks the job definition. Only the YAML syntax and the fields
+// needed by the fake are checked, unlike the schema validation of the server.
+func (f *Fake) validate(def string) (*lava.JobStruct, lava.JobErrors) {
+	var job lava.JobStruct
+	err := yaml.Unmarshal([]byte(def), &job)
+	if err != nil {
+		return nil, lava.JobErrors{"yaml": err.Error()}
+	}
+	errs := lava.JobErrors{}
+	if job.JobName == "" {
+		errs["job_name"] = "required key not provided"
+	}
+	if job.DeviceType == "" {
+		errs["device_type"] = "required key not provided"
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &job, nil
+}
+
+func (f *Fake) JobsValidate(def string, strict bool) (lava.JobErrors, error) {
+	return f.JobsValidateContext(context.Background(), def, strict)
+}
+
+// JobsValidateContext returns the errors found in the job definition. Only
+// the YAML syntax and the presence of job_name and device_type are checked.
+func (f *Fake) JobsValidateContext(ctx context.Context, def string, strict bool) (lava.JobErrors, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsValidate"); err != nil {
+		return nil, err
+	}
+
+	_, errs := f.validate(def)
+	if errs == nil {
+		errs = lava.JobErrors{}
+	}
+
+	return errs, nil
+}
+
+func (f *Fake) submit(def string) ([]int, error) {
+	job, errs := f.validate(def)
+	if errs != nil {
+		msg, _ := json.Marshal(errs)
+		return nil, invalidRequest("Problem with submitted job data: %s", msg)
+	}
+	if _, ok := f.deviceTypes[job.DeviceType]; !ok {
+		return nil, invalidRequest("Device type '%s' is unavailable.", job.DeviceType)
+	}
+	visibility := job.Visibility
+	if visibility == "" {
+		visibility = "public"
+	}
+
+	f.lastJobID++
+	j := newJob(f.lastJobID, def)
+	j.Description = job.JobName
+	j.DeviceType = job.DeviceType
+	j.Submitter = f.username
+	j.Visibility = visibility
+	j.Tags = append([]string{}, job.Tags...)
+	j.priority = priority(job.Priority)
+	f.jobs[j.ID] = j
+	f.publishJob(j)
+
+	return []int{j.ID}, nil
+}
+
+func newJob(id int, def string) *job {
+	return &job{JobState: lava.JobState{ID: id,
+		State:      "Submitted",
+		Health:     "Unknown",
+		SubmitTime: now(),
+		Pipeline:   true,
+		Tags:       []string{},
+	},
+		definition: def,
+	}
+}
+
+func (f *Fake) JobsSubmitString(def string) ([]int, error) {
+	return f.JobsSubmitStringContext(context.Background(), def)
+}
+
+// JobsSubmitStringContext adds a job in the "Submitted" state. Use StartJob
+// and SetJobState to run it.
+func (f *Fake) JobsSubmitStringContext(ctx context.Context, def string) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsSubmitString"); err != nil {
+		return nil, err
+	}
+
+	return f.submit(def)
+}
+
+func (f *Fake) JobsSubmit(def *lava.JobStruct) ([]int, error) {
+	return f.JobsSubmitContext(context.Background(), def)
+}
+
+func (f *Fake) JobsSubmitContext(ctx context.Context, def *lava.JobStruct) ([]int, error) {
+	d, err := yaml.Marshal(def)
+	if err != nil {
+		return []int{-1}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsSubmit"); err != nil {
+		return nil, err
+	}
+
+	return f.submit(string(d))
+}
+
+func (f *Fake) JobsConfiguration(id int) (*lava.JobConfiguration, error) {
+	return f.JobsConfigurationContext(context.Background(), id)
+}
+
+// JobsConfigurationContext returns the job definition, the dictionary of the
+// device and the configuration of the worker the job is running on
+func (f *Fake) JobsConfigurationContext(ctx context.Context, id int) (*lava.JobConfiguration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsConfiguration"); err != nil {
+		return nil, err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return nil, err
+	}
+	ret := lava.JobConfiguration{Definition: j.definition}
+	if d, ok := f.devices[j.Device]; ok {
+		ret.Device = d.dict
+		if w, ok := f.workers[d.Worker]; ok {
+			ret.Dispatcher = w.config
+			ret.Env = w.env
+		}
+	}
+
+	return &ret, nil
+}
+
+func (f *Fake) JobsResubmit(id int) ([]int, error) {
+	return f.JobsResubmitContext(context.Background(), id)
+}
+
+func (f *Fake) JobsResubmitContext(ctx context.Context, id int) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsResubmit"); err != nil {
+		return nil, err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.submit(j.definition)
+}
+
+func (f *Fake) JobsResolve(number string) (int, error) {
+	return f.JobsResolveContext(context.Background(), number)
+}
+
+// JobsResolveContext returns plain job IDs as is. The fake doesn't support
+// multinode jobs, so sub_ids are never found.
+func (f *Fake) JobsResolveContext(ctx context.Context, number string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsResolve"); err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, notFound("Job '%s' was not found.", number)
+	}
+
+	return id, nil
+}
+
+// finish moves the job to the Finished state and frees the device
+func (f *Fake) finish(j *job, health string) {
+	if j.State == "Finished" {
+		return
+	}
+	j.State = "Finished"
+	j.Health = health
+	j.EndTime = now()
+	f.publishJob(j)
+
+	if d, ok := f.devices[j.Device]; ok && d.CurrentJob == j.ID {
+		d.CurrentJob = 0
+		d.State = "Idle"
+		f.publishDevice(d)
+	}
+}
+
+func (f *Fake) JobsCancel(id int) error {
+	return f.JobsCancelContext(context.Background(), id)
+}
+
+// JobsCancelContext cancels the job. Unlike on a real server, the job is
+// finished immediately instead of going through the "Canceling" state.
+func (f *Fake) JobsCancelContext(ctx context.Context, id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsCancel"); err != nil {
+		return err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	f.finish(j, "Canceled")
+
+	return nil
+}
+
+func (f *Fake) JobsFail(id int) error {
+	return f.JobsFailContext(context.Background(), id)
+}
+
+func (f *Fake) JobsFailContext(ctx context.Context, id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsFail"); err != nil {
+		return err
+	}
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	f.finish(j, "Incomplete")
+
+	return nil
+}
+
+func (f *Fake) JobsLogs(id int, raw bool) (*lava.JobsLogs, error) {
+	return f.JobsLogsContext(context.Background(), id, raw)
+}
+
+func (f *Fake) JobsLogsContext(ctx context.Context, id int, raw bool) (*lava.JobsLogs, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsLogs"); err != nil {
+		return nil, err
+	}
+
+	return f.logs(id, 0, 0, raw)
+}
+
+func (f *Fake) JobsLogsRange(id int, start int, end int, raw bool) (*lava.JobsLogs, error) {
+	return f.JobsLogsRangeContext(context.Background(), id, start, end, raw)
+}
+
+func (f *Fake) JobsLogsRangeContext(ctx context.Context, id int, start int, end int, raw bool) (*lava.JobsLogs, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "JobsLogsRange"); err != nil {
+		return nil, err
+	}
+
+	return f.logs(id, start, end, raw)
+}
+
+func (f *Fake) logs(id int, start int, end int, raw bool) (*lava.JobsLogs, error) {
+	j, err := f.job(id)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(j.logs, "\n")
+	lines = lines[:len(lines)-1]
+	if end <= 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		start = end
+	}
+
+	ret := lava.JobsLogs{Finished: j.State == "Finished",
+		Data:  strings.Join(lines[start:end], ""),
+		Lines: end - start,
+	}
+	if raw {
+		return &ret, nil
+	}
+	err = yaml.Unmarshal([]byte(ret.Data), &ret.Decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// StartJob schedules the job on the device and sets both to "Running"
+func (f *Fake) StartJob(id int, hostname string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	d, err := f.device(hostname)
+	if err != nil {
+		return err
+	}
+	if d.CurrentJob != 0 {
+		return invalidRequest("Device '%s' is busy running job %d", hostname, d.CurrentJob)
+	}
+
+	j.Device = hostname
+	j.State = "Running"
+	j.StartTime = now()
+	d.CurrentJob = id
+	d.State = "Running"
+	f.publishJob(j)
+	f.publishDevice(d)
+
+	return nil
+}
+
+// SetJobState sets the state and health of the job, like "Finished" and
+// "Complete", and publishes a testjob event. Finished jobs free their device.
+func (f *Fake) SetJobState(id int, state string, health string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	if title(state) == "Finished" {
+		f.finish(j, title(health))
+		return nil
+	}
+	j.State = title(state)
+	j.Health = title(health)
+	if j.State == "Running" && j.StartTime.IsZero() {
+		j.StartTime = now()
+	}
+	f.publishJob(j)
+
+	return nil
+}
+
+// AppendJobLog adds a line to the log of the job
+func (f *Fake) AppendJobLog(id int, level string, msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	m, _ := json.Marshal(msg)
+	j.logs += `- {"dt": "` + now().Format("2006-01-02T15:04:05.000000") + `", "lvl": "` + level + `", "msg": ` + string(m) + "}\n"
+
+	return nil
+}
@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"gopkg.in/yaml.v2"
+)
+
+// SetJobResults sets the test results of the job
+func (f *Fake) SetJobResults(id int, results lava.Result) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	j, err := f.job(id)
+	if err != nil {
+		return err
+	}
+	j.results = append(lava.Result{}, results...)
+	for i := range j.results {
+		j.results[i].Job = strconv.Itoa(id)
+	}
+
+	return nil
+}
+
+// results returns the results of the job, filtered by suite and test case
+// unless empty
+func (f *Fake) results(id int, suite string, testCase string) (lava.Result, error) {
+	j, err := f.job(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := lava.Result{}
+	for _, r := range j.results {
+		if suite != "" && r.Suite != suite {
+			continue
+		}
+		if testCase != "" && r.Name != testCase {
+			continue
+		}
+		ret = append(ret, r)
+	}
+	if testCase != "" && len(ret) == 0 {
+		return nil, notFound("Test case '%s' was not found.", testCase)
+	}
+	if suite != "" && len(ret) == 0 {
+		return nil, notFound("Test suite '%s' was not found.", suite)
+	}
+
+	return ret, nil
+}
+
+func encodeYAML(results lava.Result, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	d, err := yaml.Marshal(results)
+	return string(d), err
+}
+
+func encodeJSON(results lava.Result, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	d, err := json.Marshal(results)
+	return string(d), err
+}
+
+func encodeCSV(results lava.Result, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"job", "suite", "result", "measurement", "duration", "logged",
+		"level", "url", "name", "id", "log_start_line", "log_end_line"})
+	for _, r := range results {
+		w.Write([]string{r.Job, r.Suite, r.Result, r.Measurement, r.Metadata.Duration, r.Logged,
+			r.Level, r.URL, r.Name, r.ID, r.LogLineStart, r.LogLineEnd})
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+func (f *Fake) ResultsAsYAML(id int) (string, error) {
+	return f.ResultsAsYAMLContext(context.Background(), id)
+}
+
+func (f *Fake) ResultsAsYAMLContext(ctx context.Context, id int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsAsYAML"); err != nil {
+		return "", err
+	}
+
+	return encodeYAML(f.results(id, "", ""))
+}
+
+func (f *Fake) Results(id int) (lava.Result, error) {
+	return f.ResultsContext(context.Background(), id)
+}
+
+func (f *Fake) ResultsContext(ctx context.Context, id int) (lava.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Results"); err != nil {
+		return nil, err
+	}
+
+	return f.results(id, "", "")
+}
+
+func (f *Fake) ResultsAsJSON(id int) (string, error) {
+	return f.ResultsAsJSONContext(context.Background(), id)
+}
+
+func (f *Fake) ResultsAsJSONContext(ctx context.Context, id int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsAsJSON"); err != nil {
+		return "", err
+	}
+
+	return encodeJSON(f.results(id, "", ""))
+}
+
+func (f *Fake) ResultsAsCSV(id int) (string, error) {
+	return f.ResultsAsCSVContext(context.Background(), id)
+}
+
+func (f *Fake) ResultsAsCSVContext(ctx context.Context, id int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsAsCSV"); err != nil {
+		return "", err
+	}
+
+	return encodeCSV(f.results(id, "", ""))
+}
+
+func (f *Fake) ResultsSuiteAsYAML(id int, suite string) (string, error) {
+	return f.ResultsSuiteAsYAMLContext(context.Background(), id, suite)
+}
+
+func (f *Fake) ResultsSuiteAsYAMLContext(ctx context.Context, id int, suite string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsSuiteAsYAML"); err != nil {
+		return "", err
+	}
+
+	return encodeYAML(f.results(id, suite, ""))
+}
+
+func (f *Fake) ResultsSuiteAsCSV(id int, suite string) (string, error) {
+	return f.ResultsSuiteAsCSVContext(context.Background(), id, suite)
+}
+
+func (f *Fake) ResultsSuiteAsCSVContext(ctx context.Context, id int, suite string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsSuiteAsCSV"); err != nil {
+		return "", err
+	}
+
+	return encodeCSV(f.results(id, suite, ""))
+}
+
+func (f *Fake) ResultsSuite(id int, suite string) (lava.Result, error) {
+	return f.ResultsSuiteContext(context.Background(), id, suite)
+}
+
+func (f *Fake) ResultsSuiteContext(ctx context.Context, id int, suite string) (lava.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsSuite"); err != nil {
+		return nil, err
+	}
+
+	return f.results(id, suite, "")
+}
+
+func (f *Fake) ResultsSuiteAsJSON(id int, suite string) (string, error) {
+	return f.ResultsSuiteAsJSONContext(context.Background(), id, suite)
+}
+
+func (f *Fake) ResultsSuiteAsJSONContext(ctx context.Context, id int, suite string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsSuiteAsJSON"); err != nil {
+		return "", err
+	}
+
+	return encodeJSON(f.results(id, suite, ""))
+}
+
+func (f *Fake) ResultsCaseAsYAML(id int, suite string, testCase string) (string, error) {
+	return f.ResultsCaseAsYAMLContext(context.Background(), id, suite, testCase)
+}
+
+func (f *Fake) ResultsCaseAsYAMLContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsCaseAsYAML"); err != nil {
+		return "", err
+	}
+
+	return encodeYAML(f.results(id, suite, testCase))
+}
+
+func (f *Fake) ResultsCaseAsCSV(id int, suite string, testCase string) (string, error) {
+	return f.ResultsCaseAsCSVContext(context.Background(), id, suite, testCase)
+}
+
+func (f *Fake) ResultsCaseAsCSVContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsCaseAsCSV"); err != nil {
+		return "", err
+	}
+
+	return encodeCSV(f.results(id, suite, testCase))
+}
+
+func (f *Fake) ResultsCase(id int, suite string, testCase string) (lava.Result, error) {
+	return f.ResultsCaseContext(context.Background(), id, suite, testCase)
+}
+
+func (f *Fake) ResultsCaseContext(ctx context.Context, id int, suite string, testCase string) (lava.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsCase"); err != nil {
+		return nil, err
+	}
+
+	return f.results(id, suite, testCase)
+}
+
+func (f *Fake) ResultsCaseAsJSON(id int, suite string, testCase string) (string, error) {
+	return f.ResultsCaseAsJSONContext(context.Background(), id, suite, testCase)
+}
+
+func (f *Fake) ResultsCaseAsJSONContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ResultsCaseAsJSON"); err != nil {
+		return "", err
+	}
+
+	return encodeJSON(f.results(id, suite, testCase))
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+// methods are the XMLRPC methods of the server that are used by package lava
+var methods = []string{
+	"auth.groups.list",
+	"auth.groups.perms.add",
+	"auth.groups.perms.delete",
+	"auth.groups.perms.list",
+	"auth.groups.show",
+	"auth.users.add",
+	"auth.users.delete",
+	"auth.users.list",
+	"auth.users.show",
+	"auth.users.update",
+	"results.get_testcase_results_csv",
+	"results.get_testcase_results_yaml",
+	"results.get_testjob_results_csv",
+	"results.get_testjob_results_yaml",
+	"results.get_testsuite_results_csv",
+	"results.get_testsuite_results_yaml",
+	"scheduler.device_types.add",
+	"scheduler.device_types.aliases.add",
+	"scheduler.device_types.aliases.delete",
+	"scheduler.device_types.aliases.list",
+	"scheduler.device_types.get_health_check",
+	"scheduler.device_types.get_template",
+	"scheduler.device_types.list",
+	"scheduler.device_types.set_health_check",
+	"scheduler.device_types.set_template",
+	"scheduler.device_types.show",
+	"scheduler.device_types.update",
+	"scheduler.devices.add",
+	"scheduler.devices.get_dictionary",
+	"scheduler.devices.list",
+	"scheduler.devices.set_dictionary",
+	"scheduler.devices.show",
+	"scheduler.devices.tags.add",
+	"scheduler.devices.tags.delete",
+	"scheduler.devices.tags.list",
+	"scheduler.devices.update",
+	"scheduler.jobs.cancel",
+	"scheduler.jobs.configuration",
+	"scheduler.jobs.definition",
+	"scheduler.jobs.fail",
+	"scheduler.jobs.list",
+	"scheduler.jobs.logs",
+	"scheduler.jobs.queue",
+	"scheduler.jobs.resubmit",
+	"scheduler.jobs.show",
+	"scheduler.jobs.submit",
+	"scheduler.jobs.validate",
+	"scheduler.tags.add",
+	"scheduler.tags.delete",
+	"scheduler.tags.list",
+	"scheduler.tags.show",
+	"scheduler.workers.add",
+	"scheduler.workers.get_config",
+	"scheduler.workers.get_env",
+	"scheduler.workers.list",
+	"scheduler.workers.set_config",
+	"scheduler.workers.set_env",
+	"scheduler.workers.show",
+	"scheduler.workers.update",
+	"system.api_version",
+	"system.listMethods",
+	"system.methodHelp",
+	"system.methodSignature",
+	"system.version",
+	"system.whoami",
+}
+
+func (f *Fake) SystemVersion() (string, error) {
+	return f.SystemVersionContext(context.Background())
+}
+
+func (f *Fake) SystemVersionContext(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemVersion"); err != nil {
+		return "", err
+	}
+
+	return f.version, nil
+}
+
+func (f *Fake) SystemAPIVersion() (int, error) {
+	return f.SystemAPIVersionContext(context.Background())
+}
+
+func (f *Fake) SystemAPIVersionContext(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemAPIVersion"); err != nil {
+		return 0, err
+	}
+
+	return f.apiVersion, nil
+}
+
+func (f *Fake) SystemWhoami() (string, error) {
+	return f.SystemWhoamiContext(context.Background())
+}
+
+func (f *Fake) SystemWhoamiContext(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemWhoami"); err != nil {
+		return "", err
+	}
+
+	return f.username, nil
+}
+
+func (f *Fake) SystemListMethods() ([]string, error) {
+	return f.SystemListMethodsContext(context.Background())
+}
+
+func (f *Fake) SystemListMethodsContext(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemListMethods"); err != nil {
+		return nil, err
+	}
+
+	return append([]string{}, methods...), nil
+}
+
+// method returns an error unless the server provides the method
+func method(name string) error {
+	if !contains(methods, name) {
+		return &lava.InvalidRequestError{Fault: lava.Fault{Code: -32601, Message: "method \"" + name + "\" is not supported"}}
+	}
+	return nil
+}
+
+func (f *Fake) SystemMethodHelp(name string) (string, error) {
+	return f.SystemMethodHelpContext(context.Background(), name)
+}
+
+// SystemMethodHelpContext returns an empty documentation for all known methods
+func (f *Fake) SystemMethodHelpContext(ctx context.Context, name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemMethodHelp"); err != nil {
+		return "", err
+	}
+
+	return "", method(name)
+}
+
+func (f *Fake) SystemMethodSignature(name string) ([][]string, error) {
+	return f.SystemMethodSignatureContext(context.Background(), name)
+}
+
+// SystemMethodSignatureContext returns no signatures for all known methods,
+// like the server does
+func (f *Fake) SystemMethodSignatureContext(ctx context.Context, name string) ([][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemMethodSignature"); err != nil {
+		return nil, err
+	}
+
+	return nil, method(name)
+}
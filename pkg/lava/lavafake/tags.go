@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) TagsList() ([]lava.Tag, error) {
+	return f.TagsListContext(context.Background())
+}
+
+func (f *Fake) TagsListContext(ctx context.Context) ([]lava.Tag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "TagsList"); err != nil {
+		return nil, err
+	}
+
+	ret := []lava.Tag{}
+	for name, description := range f.tags {
+		ret = append(ret, lava.Tag{Name: name, Description: description})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+
+	return ret, nil
+}
+
+func (f *Fake) TagsShow(name string) (*lava.TagDetail, error) {
+	return f.TagsShowContext(context.Background(), name)
+}
+
+func (f *Fake) TagsShowContext(ctx context.Context, name string) (*lava.TagDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "TagsShow"); err != nil {
+		return nil, err
+	}
+
+	description, ok := f.tags[name]
+	if !ok {
+		return nil, notFound("Tag '%s' was not found.", name)
+	}
+	ret := lava.TagDetail{Name: name, Description: description, Devices: []string{}}
+	for _, d := range f.devices {
+		if contains(d.Tags, name) {
+			ret.Devices = append(ret.Devices, d.Hostname)
+		}
+	}
+	sort.Strings(ret.Devices)
+
+	return &ret, nil
+}
+
+func (f *Fake) TagsAdd(name string, description string) error {
+	return f.TagsAddContext(context.Background(), name, description)
+}
+
+func (f *Fake) TagsAddContext(ctx context.Context, name string, description string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "TagsAdd"); err != nil {
+		return err
+	}
+
+	if _, ok := f.tags[name]; ok {
+		return invalidRequest("Tag '%s' already exists", name)
+	}
+	f.tags[name] = description
+
+	return nil
+}
+
+func (f *Fake) TagsDelete(name string) error {
+	return f.TagsDeleteContext(context.Background(), name)
+}
+
+// TagsDeleteContext deletes the tag and removes it from all devices
+func (f *Fake) TagsDeleteContext(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "TagsDelete"); err != nil {
+		return err
+	}
+
+	if _, ok := f.tags[name]; !ok {
+		return notFound("Tag '%s' was not found.", name)
+	}
+	delete(f.tags, name)
+	for _, d := range f.devices {
+		d.Tags = remove(d.Tags, name)
+	}
+
+	return nil
+}
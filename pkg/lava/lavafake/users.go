@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) user(username string) (*lava.UserDetail, error) {
+	u, ok := f.users[username]
+	if !ok {
+		return nil, notFound("User '%s' was not found.", username)
+	}
+	return u, nil
+}
+
+func (f *Fake) UsersList() ([]lava.User, error) {
+	return f.UsersListContext(context.Background())
+}
+
+func (f *Fake) UsersListContext(ctx context.Context) ([]lava.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersList"); err != nil {
+		return nil, err
+	}
+
+	ret := []lava.User{}
+	for _, u := range f.users {
+		ret = append(ret, lava.User{Username: u.Username,
+			FirstName:   u.FirstName,
+			LastName:    u.LastName,
+			IsActive:    u.IsActive,
+			IsStaff:     u.IsStaff,
+			IsSuperuser: u.IsSuperuser,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Username < ret[j].Username })
+
+	return ret, nil
+}
+
+func (f *Fake) UsersShow(username string) (*lava.UserDetail, error) {
+	return f.UsersShowContext(context.Background(), username)
+}
+
+func (f *Fake) UsersShowContext(ctx context.Context, username string) (*lava.UserDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersShow"); err != nil {
+		return nil, err
+	}
+
+	u, err := f.user(username)
+	if err != nil {
+		return nil, err
+	}
+	ret := *u
+	ret.Groups = []string{}
+	ret.Permissions = []string{}
+	for _, g := range f.groups {
+		if contains(g.Users, username) {
+			ret.Groups = append(ret.Groups, g.Name)
+			ret.Permissions = append(ret.Permissions, g.permissions()...)
+		}
+	}
+	sort.Strings(ret.Groups)
+	sort.Strings(ret.Permissions)
+
+	return &ret, nil
+}
+
+func (f *Fake) UsersAdd(username string, s lava.UserSettings) error {
+	return f.UsersAddContext(context.Background(), username, s)
+}
+
+// UsersAddContext adds a user. Users are active unless s.IsActive is false.
+func (f *Fake) UsersAddContext(ctx context.Context, username string, s lava.UserSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersAdd"); err != nil {
+		return err
+	}
+
+	if _, ok := f.users[username]; ok {
+		return invalidRequest("User '%s' already exists", username)
+	}
+	u := &lava.UserDetail{ID: len(f.users) + 1,
+		Username:   username,
+		IsActive:   true,
+		DateJoined: now(),
+	}
+	updateUser(u, s)
+	f.users[username] = u
+
+	return nil
+}
+
+func (f *Fake) UsersUpdate(username string, s lava.UserSettings) error {
+	return f.UsersUpdateContext(context.Background(), username, s)
+}
+
+func (f *Fake) UsersUpdateContext(ctx context.Context, username string, s lava.UserSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersUpdate"); err != nil {
+		return err
+	}
+
+	u, err := f.user(username)
+	if err != nil {
+		return err
+	}
+	updateUser(u, s)
+
+	return nil
+}
+
+func updateUser(u *lava.UserDetail, s lava.UserSettings) {
+	if s.FirstName != "" {
+		u.FirstName = s.FirstName
+	}
+	if s.LastName != "" {
+		u.LastName = s.LastName
+	}
+	if s.Email != "" {
+		u.Email = s.Email
+	}
+	if s.IsActive != nil {
+		u.IsActive = *s.IsActive
+	}
+	if s.IsStaff != nil {
+		u.IsStaff = *s.IsStaff
+	}
+	if s.IsSuperuser != nil {
+		u.IsSuperuser = *s.IsSuperuser
+	}
+}
+
+func (f *Fake) UsersDelete(username string) error {
+	return f.UsersDeleteContext(context.Background(), username)
+}
+
+// UsersDeleteContext deletes the user and removes it from all groups
+func (f *Fake) UsersDeleteContext(ctx context.Context, username string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersDelete"); err != nil {
+		return err
+	}
+
+	if _, err := f.user(username); err != nil {
+		return err
+	}
+	delete(f.users, username)
+	for _, g := range f.groups {
+		g.Users = remove(g.Users, username)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lavafake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+func (f *Fake) worker(hostname string) (*worker, error) {
+	w, ok := f.workers[hostname]
+	if !ok {
+		return nil, notFound("Worker '%s' was not found.", hostname)
+	}
+	return w, nil
+}
+
+func (f *Fake) WorkersList() ([]lava.Worker, error) {
+	return f.WorkersListContext(context.Background())
+}
+
+func (f *Fake) WorkersListContext(ctx context.Context) ([]lava.Worker, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersList"); err != nil {
+		return nil, err
+	}
+
+	ret := []lava.Worker{}
+	for _, w := range f.workers {
+		ret = append(ret, lava.Worker{Hostname: w.Hostname})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Hostname < ret[j].Hostname })
+
+	return ret, nil
+}
+
+func (f *Fake) WorkersShow(hostname string) (*lava.WorkerDetail, error) {
+	return f.WorkersShowContext(context.Background(), hostname)
+}
+
+func (f *Fake) WorkersShowContext(ctx context.Context, hostname string) (*lava.WorkerDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersShow"); err != nil {
+		return nil, err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return nil, err
+	}
+	ret := w.WorkerDetail
+	ret.Devices = []string{}
+	for _, d := range f.devices {
+		if d.Worker == hostname {
+			ret.Devices = append(ret.Devices, d.Hostname)
+		}
+	}
+	sort.Strings(ret.Devices)
+	ret.DefaultConfig = w.config == ""
+	ret.DefaultEnv = w.env == ""
+
+	return &ret, nil
+}
+
+func (f *Fake) WorkersAdd(hostname string, description string, disabled bool) error {
+	return f.WorkersAddContext(context.Background(), hostname, description, disabled)
+}
+
+// WorkersAddContext adds a worker. Workers are online, unless disabled,
+// in which case their health is "Retired".
+func (f *Fake) WorkersAddContext(ctx context.Context, hostname string, description string, disabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersAdd"); err != nil {
+		return err
+	}
+
+	if _, ok := f.workers[hostname]; ok {
+		return invalidRequest("Worker '%s' already exists", hostname)
+	}
+	health := "Active"
+	if disabled {
+		health = "Retired"
+	}
+	f.workers[hostname] = &worker{WorkerDetail: lava.WorkerDetail{Hostname: hostname,
+		Description: description,
+		State:       "Online",
+		Health:      health,
+		LastPing:    now(),
+		Version:     f.version,
+	}}
+	f.publishWorker(f.workers[hostname])
+
+	return nil
+}
+
+func (f *Fake) WorkersUpdate(hostname string, description string, health string) error {
+	return f.WorkersUpdateContext(context.Background(), hostname, description, health)
+}
+
+func (f *Fake) WorkersUpdateContext(ctx context.Context, hostname string, description string, health string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersUpdate"); err != nil {
+		return err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return err
+	}
+	if description != "" {
+		w.Description = description
+	}
+	if health != "" && title(health) != w.Health {
+		w.Health = title(health)
+		f.publishWorker(w)
+	}
+
+	return nil
+}
+
+func (f *Fake) WorkersConfigGet(hostname string) (string, error) {
+	return f.WorkersConfigGetContext(context.Background(), hostname)
+}
+
+func (f *Fake) WorkersConfigGetContext(ctx context.Context, hostname string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersConfigGet"); err != nil {
+		return "", err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return "", err
+	}
+	if w.config == "" {
+		return "", notFound("Worker '%s' does not have a configuration", hostname)
+	}
+
+	return w.config, nil
+}
+
+func (f *Fake) WorkersConfigSet(hostname string, config string) error {
+	return f.WorkersConfigSetContext(context.Background(), hostname, config)
+}
+
+func (f *Fake) WorkersConfigSetContext(ctx context.Context, hostname string, config string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersConfigSet"); err != nil {
+		return err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return err
+	}
+	w.config = config
+
+	return nil
+}
+
+func (f *Fake) WorkersEnvGet(hostname string) (string, error) {
+	return f.WorkersEnvGetContext(context.Background(), hostname)
+}
+
+func (f *Fake) WorkersEnvGetContext(ctx context.Context, hostname string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersEnvGet"); err != nil {
+		return "", err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return "", err
+	}
+	if w.env == "" {
+		return "", notFound("Worker '%s' does not have an environment", hostname)
+	}
+
+	return w.env, nil
+}
+
+func (f *Fake) WorkersEnvSet(hostname string, env string) error {
+	return f.WorkersEnvSetContext(context.Background(), hostname, env)
+}
+
+func (f *Fake) WorkersEnvSetContext(ctx context.Context, hostname string, env string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "WorkersEnvSet"); err != nil {
+		return err
+	}
+
+	w, err := f.worker(hostname)
+	if err != nil {
+		return err
+	}
+	w.env = env
+
+	return nil
+}
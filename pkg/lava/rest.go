@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIs of the LAVA server, selected using ConnectionOptions.API
+const (
+	// APIXMLRPC uses the XMLRPC API for all requests. This is the default.
+	APIXMLRPC = "xmlrpc"
+	// APIREST uses the REST API v0.2 for listing and showing jobs, devices
+	// and results, which filters and paginates on the server. All other
+	// requests still use the XMLRPC API.
+	APIREST = "rest"
+)
+
+// restPath is the path of the REST API relative to the server root
+const restPath = "/api/v0.2/"
+
+// restPageSize is the number of objects fetched at once when walking all pages
+const restPageSize = 100
+
+// restURI returns the base URI of the REST API belonging to the XMLRPC URI,
+// https://lava.example.com/RPC2 becomes https://lava.example.com/api/v0.2/
+func restURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse URI: %v", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	path = strings.TrimSuffix(path, "/RPC2")
+	u.Path = path + restPath
+	u.RawQuery = ""
+
+	return u.String(), nil
+}
+
+// xmlrpcURI returns the XMLRPC URI belonging to a URI of the REST API,
+// https://lava.example.com/api/v0.2/ becomes https://lava.example.com/RPC2.
+// Other URIs are returned unchanged.
+func xmlrpcURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse URI: %v", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path+"/", restPath) {
+		return uri, nil
+	}
+	u.Path = strings.TrimSuffix(path+"/", restPath) + "/RPC2"
+
+	return u.String(), nil
+}
+
+// restError converts an error response of the REST API. The message is
+// taken from the "detail" field, as returned by the server.
+func restError(resp *http.Response, body []byte) error {
+	var detail struct {
+		Detail string `json:"detail"`
+	}
+	msg := resp.Status
+	if json.Unmarshal(body, &detail) == nil && detail.Detail != "" {
+		msg = detail.Detail
+	}
+
+	return newFaultError(resp.StatusCode, msg)
+}
+
+// restGet issues a GET request on path relative to the REST API and returns the body.
+// Errors are returned as NotFoundError, PermissionDeniedError,
+// InvalidRequestError, ServerError or TransportError, like call does.
+func (c Connection) restGet(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	uri := c.rest + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Otherwise the browsable API may return HTML
+	req.Header.Set("Accept", "application/json")
+	// The REST API doesn't support basic authentication
+	if c.opt.Token != "" && c.opt.Auth == AuthBasic {
+		req.Header.Set("Authorization", "Token "+c.opt.Token)
+	}
+
+	resp, err := c.con.Do(req)
+	if err != nil {
+		return nil, &TransportError{Err: redactError(err, c.opt.Token)}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &TransportError{Err: redactError(err, c.opt.Token)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, restError(resp, body)
+	}
+
+	return body, nil
+}
+
+// restGetJSON is like restGet but decodes the JSON document into reply
+func (c Connection) restGetJSON(ctx context.Context, path string, query url.Values, reply interface{}) error {
+	body, err := c.restGet(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, reply)
+	if err != nil {
+		return fmt.Errorf("Invalid server response: %v", err)
+	}
+
+	return nil
+}
+
+// restPage is a single page of a list as returned by the REST API
+type restPage struct {
+	Count   int             `json:"count"`
+	Next    string          `json:"next"`
+	Results json.RawMessage `json:"results"`
+}
+
+// restJob is a job as returned by the REST API
+type restJob struct {
+	ID                  int       `json:"id"`
+	Submitter           string    `json:"submitter"`
+	Description         string    `json:"description"`
+	HealthCheck         bool      `json:"health_check"`
+	RequestedDeviceType string    `json:"requested_device_type"`
+	ActualDevice        string    `json:"actual_device"`
+	SubmitTime          time.Time `json:"submit_time"`
+	StartTime           time.Time `json:"start_time"`
+	EndTime             time.Time `json:"end_time"`
+	State               string    `json:"state"`
+	Health              string    `json:"health"`
+	Visibility          string    `json:"visibility"`
+	Definition          string    `json:"definition"`
+	FailureComment      string    `json:"failure_comment"`
+}
+
+func (j restJob) listing() JobsListing {
+	return JobsListing{ID: j.ID,
+		Description: j.Description,
+		DeviceType:  j.RequestedDeviceType,
+		Health:      j.Health,
+		State:       j.State,
+		Submitter:   j.Submitter,
+		SubmitTime:  j.SubmitTime,
+	}
+}
+
+// state returns the job state. The REST API returns the IDs of the tags,
+// so Tags is left empty.
+func (j restJob) state() *JobState {
+	return &JobState{ID: j.ID,
+		Description:    j.Description,
+		DeviceType:     j.RequestedDeviceType,
+		Device:         j.ActualDevice,
+		State:          j.State,
+		Health:         j.Health,
+		SubmitTime:     j.SubmitTime,
+		StartTime:      j.StartTime,
+		EndTime:        j.EndTime,
+		FailureComment: j.FailureComment,
+		HealthCheck:    j.HealthCheck,
+		Pipeline:       true,
+		Visibility:     j.Visibility,
+		Submitter:      j.Submitter,
+	}
+}
+
+// restChoice converts a state or health to the capitalized form used by the
+// REST API, like "Finished"
+func restChoice(s string) string {
+	if s == "" {
+		return s
+	}
+	s = strings.ToLower(s)
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// restJobsQuery returns the query parameters filtering jobs on the server
+func restJobsQuery(filter JobsFilter) url.Values {
+	q := url.Values{}
+	q.Set("ordering", "-id")
+	if filter.State != "" {
+		q.Set("state", restChoice(filter.State))
+	}
+	if filter.Health != "" {
+		q.Set("health", restChoice(filter.Health))
+	}
+	if filter.Submitter != "" {
+		q.Set("submitter__username", filter.Submitter)
+	}
+	if filter.DeviceType != "" {
+		q.Set("requested_device_type", filter.DeviceType)
+	}
+	if !filter.SubmittedAfter.IsZero() {
+		q.Set("submit_time__gte", filter.SubmittedAfter.UTC().Format(time.RFC3339Nano))
+	}
+	if !filter.SubmittedBefore.IsZero() {
+		q.Set("submit_time__lt", filter.SubmittedBefore.UTC().Format(time.RFC3339Nano))
+	}
+	return q
+}
+
+// restJobs returns a single page of the jobs matching the filter, newest first.
+// The description expression isn't supported by the server and is ignored.
+func (c Connection) restJobs(ctx context.Context, filter JobsFilter, offset int, limit int) ([]JobsListing, error) {
+	q := restJobsQuery(filter)
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+
+	var page restPage
+	err := c.restGetJSON(ctx, "jobs/", q, &page)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []restJob
+	err = json.Unmarshal(page.Results, &jobs)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid server response: %v", err)
+	}
+
+	ret := []JobsListing{}
+	for _, j := range jobs {
+		ret = append(ret, j.listing())
+	}
+	return ret, nil
+}
+
+// restJobsList filters and paginates on the server. Only a filter on the
+// description has to be applied locally.
+func (c Connection) restJobsList(ctx context.Context, filter JobsFilter, start int, limit int) ([]JobsListing, error) {
+	list := func(offset int, n int) ([]JobsListing, error) {
+		return c.restJobs(ctx, filter, offset, n)
+	}
+	if filter.Description != nil {
+		return filterJobs(filter, start, limit, newJobsIterator(0, restPageSize, list))
+	}
+	pageSize := restPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	return filterJobs(filter, 0, limit, newJobsIterator(start, pageSize, list))
+}
+
+func (c Connection) restJob(ctx context.Context, id int) (*restJob, error) {
+	var ret restJob
+	err := c.restGetJSON(ctx, fmt.Sprintf("jobs/%d/", id), nil, &ret)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// restDevice is a device as returned by the REST API
+type restDevice struct {
+	Hostname    string `json:"hostname"`
+	DeviceType  string `json:"device_type"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	Health      string `json:"health"`
+	WorkerHost  string `json:"worker_host"`
+}
+
+// restRunningJobs returns the IDs of the running jobs by device. The REST API
+// doesn't return the current job as part of the device, so all running jobs
+// are fetched at once.
+func (c Connection) restRunningJobs(ctx context.Context) (map[string]int, error) {
+	q := url.Values{}
+	q.Set("state", "Running")
+	q.Set("ordering", "-id")
+
+	ret := map[string]int{}
+	for offset := 0; ; {
+		q.Set("offset", strconv.Itoa(offset))
+		q.Set("limit", strconv.Itoa(restPageSize))
+
+		var page restPage
+		err := c.restGetJSON(ctx, "jobs/", q, &page)
+		if err != nil {
+			return nil, err
+		}
+		var jobs []restJob
+		err = json.Unmarshal(page.Results, &jobs)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid server response: %v", err)
+		}
+		for _, j := range jobs {
+			if _, ok := ret[j.ActualDevice]; !ok && j.ActualDevice != "" {
+				ret[j.ActualDevice] = j.ID
+			}
+		}
+		offset += len(jobs)
+		if page.Next == "" || len(jobs) == 0 {
+			return ret, nil
+		}
+	}
+}
+
+// restDevicesList walks all pages of the devices matching the filter
+func (c Connection) restDevicesList(ctx context.Context, filter DevicesFilter) ([]DeviceList, error) {
+	q := url.Values{}
+	q.Set("ordering", "hostname")
+	if filter.DeviceType != "" {
+		q.Set("device_type", filter.DeviceType)
+	}
+	if filter.State != "" {
+		q.Set("state", restChoice(filter.State))
+	}
+	if filter.Health != "" {
+		q.Set("health", restChoice(filter.Health))
+	}
+
+	ret := []DeviceList{}
+	running := false
+	for offset := 0; ; {
+		q.Set("offset", strconv.Itoa(offset))
+		q.Set("limit", strconv.Itoa(restPageSize))
+
+		var page restPage
+		err := c.restGetJSON(ctx, "devices/", q, &page)
+		if err != nil {
+			return nil, err
+		}
+		var devices []restDevice
+		err = json.Unmarshal(page.Results, &devices)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid server response: %v", err)
+		}
+		for _, d := range devices {
+			running = running || !strings.EqualFold(d.State, "Idle")
+			ret = append(ret, DeviceList{Hostname: d.Hostname,
+				Type:     d.DeviceType,
+				State:    d.State,
+				Health:   d.Health,
+				Pipeline: true,
+			})
+		}
+		offset += len(devices)
+		if page.Next == "" || len(devices) == 0 {
+			break
+		}
+	}
+
+	// Idle devices don't need a lookup
+	if !running {
+		return ret, nil
+	}
+	jobs, err := c.restRunningJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		ret[i].CurrentJob = jobs[ret[i].Hostname]
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,351 @@
+package lava
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// restServer serves the REST API v0.2 for the given jobs, newest first, and
+// an XMLRPC endpoint returning the version
+type restServer struct {
+	jobs    []map[string]interface{}
+	devices []map[string]interface{}
+	queries []url.Values
+	auth    []string
+	accept  []string
+}
+
+func (s *restServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	s.accept = append(s.accept, r.Header.Get("Accept"))
+	q := r.URL.Query()
+	switch {
+	case r.URL.Path == "/RPC2":
+		w.Write([]byte(`<?xml version="1.0"?><methodResponse><params><param>` +
+			`<value><string>2020.01</string></value></param></params></methodResponse>`))
+	case r.URL.Path == "/api/v0.2/jobs/":
+		s.queries = append(s.queries, q)
+		var list []map[string]interface{}
+		for _, j := range s.jobs {
+			if (q.Get("state") == "" || q.Get("state") == j["state"]) &&
+				(q.Get("submitter__username") == "" || q.Get("submitter__username") == j["submitter"]) &&
+				(q.Get("actual_device") == "" || q.Get("actual_device") == j["actual_device"]) {
+				list = append(list, j)
+			}
+		}
+		s.page(w, q, list)
+	case r.URL.Path == "/api/v0.2/devices/":
+		s.queries = append(s.queries, q)
+		var list []map[string]interface{}
+		for _, d := range s.devices {
+			if (q.Get("device_type") == "" || q.Get("device_type") == d["device_type"]) &&
+				(q.Get("state") == "" || q.Get("state") == d["state"]) {
+				list = append(list, d)
+			}
+		}
+		s.page(w, q, list)
+	case r.URL.Path == "/api/v0.2/jobs/1/":
+		json.NewEncoder(w).Encode(s.jobs[len(s.jobs)-1])
+	case r.URL.Path == "/api/v0.2/jobs/1/csv/":
+		w.Write([]byte("job,suite,result\n1,lava,pass\n"))
+	case r.URL.Path == "/api/v0.2/jobs/2/":
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"detail":"You do not have permission to perform this action."}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail":"Not found."}`))
+	}
+}
+
+func (s *restServer) page(w http.ResponseWriter, q url.Values, list []map[string]interface{}) {
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if offset > len(list) {
+		offset = len(list)
+	}
+	end := len(list)
+	next := ""
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		next = "http://next"
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":   len(list),
+		"next":    next,
+		"results": list[offset:end],
+	})
+}
+
+func newRestServer(t *testing.T) (*restServer, *httptest.Server, *Connection) {
+	s := &restServer{}
+	submitted := time.Date(2020, 12, 24, 18, 0, 0, 0, time.UTC)
+	for id := 5; id > 0; id-- {
+		submitter := "alice"
+		if id%2 == 0 {
+			submitter = "bob"
+		}
+		state := "Finished"
+		if id == 5 {
+			state = "Running"
+		}
+		s.jobs = append(s.jobs, map[string]interface{}{
+			"id":                    id,
+			"submitter":             submitter,
+			"description":           fmt.Sprintf("job %d", id),
+			"requested_device_type": "qemu",
+			"actual_device":         "qemu01",
+			"submit_time":           submitted.Add(time.Duration(id) * time.Hour).Format(time.RFC3339),
+			"start_time":            nil,
+			"state":                 state,
+			"health":                "Complete",
+			"definition":            "job_name: test\n",
+		})
+	}
+	s.devices = []map[string]interface{}{
+		{"hostname": "qemu01", "device_type": "qemu", "state": "Running", "health": "Good"},
+		{"hostname": "qemu02", "device_type": "qemu", "state": "Idle", "health": "Good"},
+		{"hostname": "x86-01", "device_type": "x86", "state": "Idle", "health": "Maintenance"},
+	}
+	srv := httptest.NewServer(s)
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{},
+		Token: "secret",
+		API:   APIREST,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, srv, c
+}
+
+func TestRestURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"https://lava.example.com/RPC2", "https://lava.example.com/api/v0.2/"},
+		{"https://lava.example.com/RPC2/", "https://lava.example.com/api/v0.2/"},
+		{"http://localhost:8000/lava/RPC2", "http://localhost:8000/lava/api/v0.2/"},
+	}
+	for _, tt := range tests {
+		got, err := restURI(tt.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("restURI(%s) = %s, want %s", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestXmlrpcURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"https://lava.example.com/RPC2", "https://lava.example.com/RPC2"},
+		{"https://lava.example.com/api/v0.2/", "https://lava.example.com/RPC2"},
+		{"https://lava.example.com/api/v0.2", "https://lava.example.com/RPC2"},
+		{"http://localhost:8000/lava/api/v0.2/", "http://localhost:8000/lava/RPC2"},
+	}
+	for _, tt := range tests {
+		got, err := xmlrpcURI(tt.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("xmlrpcURI(%s) = %s, want %s", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestConnectByURI_API(t *testing.T) {
+	if _, err := ConnectByURI("http://localhost/RPC2", "", ConnectionOptions{API: "soap"}); err == nil {
+		t.Errorf("ConnectByURI() expected error for unknown API")
+	}
+	c, err := ConnectByURI("http://localhost/RPC2", "", ConnectionOptions{Token: "secret", API: APIREST})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.opt.Auth != AuthToken || c.rest != "http://localhost/api/v0.2/" {
+		t.Errorf("ConnectByURI() auth = %s, rest = %s", c.opt.Auth, c.rest)
+	}
+	// URIs of the REST API still use XMLRPC for all other requests
+	c, err = ConnectByURI("http://localhost/api/v0.2/", "", ConnectionOptions{Token: "secret", API: APIREST})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.uri != "http://localhost/RPC2" || c.rest != "http://localhost/api/v0.2/" {
+		t.Errorf("ConnectByURI() uri = %s, rest = %s", c.uri, c.rest)
+	}
+	c, err = ConnectByURI("http://localhost/RPC2", "", ConnectionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.opt.API != APIXMLRPC || c.rest != "" {
+		t.Errorf("ConnectByURI() api = %s, rest = %s, want xmlrpc", c.opt.API, c.rest)
+	}
+}
+
+func TestConnection_restJobsListFiltered(t *testing.T) {
+	s, srv, c := newRestServer(t)
+	defer srv.Close()
+
+	list, err := c.JobsListFiltered(JobsFilter{State: "FINISHED", Submitter: "alice"}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != 3 || list[1].ID != 1 || list[0].DeviceType != "qemu" {
+		t.Errorf("JobsListFiltered() = %+v, want jobs 3 and 1", list)
+	}
+	q := s.queries[0]
+	if q.Get("state") != "Finished" || q.Get("submitter__username") != "alice" || q.Get("ordering") != "-id" {
+		t.Errorf("query = %v", q)
+	}
+	if s.auth[0] != "Token secret" {
+		t.Errorf("Authorization = %s, want Token secret", s.auth[0])
+	}
+	if s.accept[0] != "application/json" {
+		t.Errorf("Accept = %s, want application/json", s.accept[0])
+	}
+
+	s.queries = nil
+	list, err = c.JobsListFiltered(JobsFilter{SubmittedAfter: time.Date(2020, 12, 24, 20, 0, 0, 0, time.UTC)}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != 4 || list[1].ID != 3 {
+		t.Errorf("JobsListFiltered() = %+v, want jobs 4 and 3", list)
+	}
+	q = s.queries[0]
+	if q.Get("submit_time__gte") != "2020-12-24T20:00:00Z" || q.Get("offset") != "1" || q.Get("limit") != "2" {
+		t.Errorf("query = %v", q)
+	}
+
+	list, err = c.JobsListFiltered(JobsFilter{Description: regexp.MustCompile("job [24]")}, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != 2 {
+		t.Errorf("JobsListFiltered() = %+v, want job 2", list)
+	}
+}
+
+func TestConnection_restJobs(t *testing.T) {
+	_, srv, c := newRestServer(t)
+	defer srv.Close()
+
+	job, err := c.JobsShow(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != 1 || job.Device != "qemu01" || job.Submitter != "alice" || !job.StartTime.IsZero() ||
+		!job.SubmitTime.Equal(time.Date(2020, 12, 24, 19, 0, 0, 0, time.UTC)) {
+		t.Errorf("JobsShow() = %+v", job)
+	}
+	def, err := c.JobsDefinition(1)
+	if err != nil || def != "job_name: test\n" {
+		t.Errorf("JobsDefinition() = %q, %v", def, err)
+	}
+	csv, err := c.ResultsAsCSV(1)
+	if err != nil || csv != "job,suite,result\n1,lava,pass\n" {
+		t.Errorf("ResultsAsCSV() = %q, %v", csv, err)
+	}
+
+	_, err = c.JobsShow(2)
+	if !errors.Is(err, ErrPermissionDenied) || !strings.Contains(err.Error(), "You do not have permission") {
+		t.Errorf("JobsShow() error = %v, want ErrPermissionDenied", err)
+	}
+	_, err = c.JobsShow(42)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("JobsShow() error = %v, want ErrNotFound", err)
+	}
+
+	// Methods not supported by the REST API still use XMLRPC
+	v, err := c.SystemVersion()
+	if err != nil || v != "2020.01" {
+		t.Errorf("SystemVersion() = %s, %v", v, err)
+	}
+}
+
+func TestConnection_restDevicesListFiltered(t *testing.T) {
+	s, srv, c := newRestServer(t)
+	defer srv.Close()
+
+	list, err := c.DevicesListFiltered(DevicesFilter{DeviceType: "qemu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Hostname != "qemu01" || list[0].CurrentJob != 5 || list[1].CurrentJob != 0 {
+		t.Errorf("DevicesListFiltered() = %+v", list)
+	}
+	if q := s.queries[0]; q.Get("device_type") != "qemu" {
+		t.Errorf("query = %v", q)
+	}
+	// The running jobs are fetched using a single query
+	if len(s.queries) != 2 || s.queries[1].Get("state") != "Running" {
+		t.Errorf("queries = %v", s.queries)
+	}
+
+	s.queries = nil
+	list, err = c.DevicesList()
+	if err != nil || len(list) != 3 || len(s.queries) != 2 {
+		t.Errorf("DevicesList() = %+v, %v", list, err)
+	}
+
+	// Idle devices don't need a lookup
+	s.queries = nil
+	list, err = c.DevicesListFiltered(DevicesFilter{State: "IDLE"})
+	if err != nil || len(list) != 2 || len(s.queries) != 1 {
+		t.Errorf("DevicesListFiltered() = %+v, %v, queries %v", list, err, s.queries)
+	}
+}
+
+func TestConnection_xmlrpcJobsListFiltered(t *testing.T) {
+	var starts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		starts = append(starts, string(body))
+		var values string
+		if len(starts) == 1 {
+			for id := 100; id > 0; id-- {
+				submitter := "alice"
+				if id%2 == 0 {
+					submitter = "bob"
+				}
+				values += fmt.Sprintf(`<value><struct>`+
+					`<member><name>id</name><value><int>%d</int></value></member>`+
+					`<member><name>submitter</name><value><string>%s</string></value></member>`+
+					`</struct></value>`, id, submitter)
+			}
+		}
+		w.Write([]byte(`<?xml version="1.0"?><methodResponse><params><param>` +
+			`<value><array><data>` + values + `</data></array></value></param></params></methodResponse>`))
+	}))
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.JobsListFiltered(JobsFilter{Submitter: "bob"}, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 49 || list[0].ID != 98 || list[48].ID != 2 {
+		t.Errorf("JobsListFiltered() returned %d jobs, first %+v", len(list), list[0])
+	}
+	if len(starts) != 2 || !strings.Contains(starts[1], "<int>100</int>") {
+		t.Errorf("expected a second page starting at 100, got %d requests", len(starts))
+	}
+}
@@ -3,7 +3,9 @@
 package lava
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 
 	"gopkg.in/yaml.v2"
 )
@@ -43,29 +45,211 @@ type Result []struct {
 
 // ResultsAsYAML represents data as returned by LAVA XMLRPC results.get_testjob_results_yaml
 func (c Connection) ResultsAsYAML(id int) (string, error) {
+	return c.ResultsAsYAMLContext(context.Background(), id)
+}
+
+// ResultsAsYAMLContext is like ResultsAsYAML but uses ctx for the request
+func (c Connection) ResultsAsYAMLContext(ctx context.Context, id int) (string, error) {
+	if c.rest != "" {
+		body, err := c.restGet(ctx, fmt.Sprintf("jobs/%d/yaml/", id), nil)
+		return string(body), err
+	}
 	var ret string
 
-	err := c.con.Call("results.get_testjob_results_yaml", id, &ret)
+	err := c.call(ctx, "results.get_testjob_results_yaml", id, &ret)
 
 	return ret, err
 }
 
 // Results represents unmarshaled data as returned by LAVA XMLRPC results.get_testjob_results_yaml
 func (c Connection) Results(id int) (Result, error) {
-	var ret Result
-	yamlStr, err := c.ResultsAsYAML(id)
+	return c.ResultsContext(context.Background(), id)
+}
 
-	err = yaml.Unmarshal([]byte(yamlStr), &ret)
+// ResultsContext is like Results but uses ctx for the request
+func (c Connection) ResultsContext(ctx context.Context, id int) (Result, error) {
+	yamlStr, err := c.ResultsAsYAMLContext(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return ret, nil
+
+	return decodeResults(yamlStr)
 }
 
 // ResultsAsJSON represents data encoded in JSON as returned by LAVA XMLRPC results.get_testjob_results_yaml
 func (c Connection) ResultsAsJSON(id int) (string, error) {
-	results, err := c.Results(id)
+	return c.ResultsAsJSONContext(context.Background(), id)
+}
+
+// ResultsAsJSONContext is like ResultsAsJSON but uses ctx for the request
+func (c Connection) ResultsAsJSONContext(ctx context.Context, id int) (string, error) {
+	results, err := c.ResultsContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeResults(results)
+}
+
+// ResultsAsCSV represents data as returned by LAVA XMLRPC results.get_testjob_results_csv
+func (c Connection) ResultsAsCSV(id int) (string, error) {
+	return c.ResultsAsCSVContext(context.Background(), id)
+}
+
+// ResultsAsCSVContext is like ResultsAsCSV but uses ctx for the request
+func (c Connection) ResultsAsCSVContext(ctx context.Context, id int) (string, error) {
+	if c.rest != "" {
+		body, err := c.restGet(ctx, fmt.Sprintf("jobs/%d/csv/", id), nil)
+		return string(body), err
+	}
+	var ret string
+
+	err := c.call(ctx, "results.get_testjob_results_csv", id, &ret)
+
+	return ret, err
+}
+
+// ResultsSuiteAsYAML represents data as returned by LAVA XMLRPC results.get_testsuite_results_yaml
+func (c Connection) ResultsSuiteAsYAML(id int, suite string) (string, error) {
+	return c.ResultsSuiteAsYAMLContext(context.Background(), id, suite)
+}
+
+// ResultsSuiteAsYAMLContext is like ResultsSuiteAsYAML but uses ctx for the request
+func (c Connection) ResultsSuiteAsYAMLContext(ctx context.Context, id int, suite string) (string, error) {
+	var ret string
+	var args []interface{}
+	args = append(args, id)
+	args = append(args, suite)
+
+	err := c.call(ctx, "results.get_testsuite_results_yaml", args, &ret)
+
+	return ret, err
+}
+
+// ResultsSuiteAsCSV represents data as returned by LAVA XMLRPC results.get_testsuite_results_csv
+func (c Connection) ResultsSuiteAsCSV(id int, suite string) (string, error) {
+	return c.ResultsSuiteAsCSVContext(context.Background(), id, suite)
+}
+
+// ResultsSuiteAsCSVContext is like ResultsSuiteAsCSV but uses ctx for the request
+func (c Connection) ResultsSuiteAsCSVContext(ctx context.Context, id int, suite string) (string, error) {
+	var ret string
+	var args []interface{}
+	args = append(args, id)
+	args = append(args, suite)
+
+	err := c.call(ctx, "results.get_testsuite_results_csv", args, &ret)
+
+	return ret, err
+}
+
+// ResultsSuite represents unmarshaled data as returned by LAVA XMLRPC results.get_testsuite_results_yaml
+func (c Connection) ResultsSuite(id int, suite string) (Result, error) {
+	return c.ResultsSuiteContext(context.Background(), id, suite)
+}
+
+// ResultsSuiteContext is like ResultsSuite but uses ctx for the request
+func (c Connection) ResultsSuiteContext(ctx context.Context, id int, suite string) (Result, error) {
+	yamlStr, err := c.ResultsSuiteAsYAMLContext(ctx, id, suite)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResults(yamlStr)
+}
+
+// ResultsSuiteAsJSON represents data encoded in JSON as returned by LAVA XMLRPC results.get_testsuite_results_yaml
+func (c Connection) ResultsSuiteAsJSON(id int, suite string) (string, error) {
+	return c.ResultsSuiteAsJSONContext(context.Background(), id, suite)
+}
+
+// ResultsSuiteAsJSONContext is like ResultsSuiteAsJSON but uses ctx for the request
+func (c Connection) ResultsSuiteAsJSONContext(ctx context.Context, id int, suite string) (string, error) {
+	results, err := c.ResultsSuiteContext(ctx, id, suite)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeResults(results)
+}
+
+// ResultsCaseAsYAML represents data as returned by LAVA XMLRPC results.get_testcase_results_yaml
+func (c Connection) ResultsCaseAsYAML(id int, suite string, testCase string) (string, error) {
+	return c.ResultsCaseAsYAMLContext(context.Background(), id, suite, testCase)
+}
+
+// ResultsCaseAsYAMLContext is like ResultsCaseAsYAML but uses ctx for the request
+func (c Connection) ResultsCaseAsYAMLContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	var ret string
+	var args []interface{}
+	args = append(args, id)
+	args = append(args, suite)
+	args = append(args, testCase)
+
+	err := c.call(ctx, "results.get_testcase_results_yaml", args, &ret)
+
+	return ret, err
+}
+
+// ResultsCaseAsCSV represents data as returned by LAVA XMLRPC results.get_testcase_results_csv
+func (c Connection) ResultsCaseAsCSV(id int, suite string, testCase string) (string, error) {
+	return c.ResultsCaseAsCSVContext(context.Background(), id, suite, testCase)
+}
+
+// ResultsCaseAsCSVContext is like ResultsCaseAsCSV but uses ctx for the request
+func (c Connection) ResultsCaseAsCSVContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	var ret string
+	var args []interface{}
+	args = append(args, id)
+	args = append(args, suite)
+	args = append(args, testCase)
+
+	err := c.call(ctx, "results.get_testcase_results_csv", args, &ret)
+
+	return ret, err
+}
+
+// ResultsCase represents unmarshaled data as returned by LAVA XMLRPC results.get_testcase_results_yaml
+func (c Connection) ResultsCase(id int, suite string, testCase string) (Result, error) {
+	return c.ResultsCaseContext(context.Background(), id, suite, testCase)
+}
+
+// ResultsCaseContext is like ResultsCase but uses ctx for the request
+func (c Connection) ResultsCaseContext(ctx context.Context, id int, suite string, testCase string) (Result, error) {
+	yamlStr, err := c.ResultsCaseAsYAMLContext(ctx, id, suite, testCase)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResults(yamlStr)
+}
+
+// ResultsCaseAsJSON represents data encoded in JSON as returned by LAVA XMLRPC results.get_testcase_results_yaml
+func (c Connection) ResultsCaseAsJSON(id int, suite string, testCase string) (string, error) {
+	return c.ResultsCaseAsJSONContext(context.Background(), id, suite, testCase)
+}
+
+// ResultsCaseAsJSONContext is like ResultsCaseAsJSON but uses ctx for the request
+func (c Connection) ResultsCaseAsJSONContext(ctx context.Context, id int, suite string, testCase string) (string, error) {
+	results, err := c.ResultsCaseContext(ctx, id, suite, testCase)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeResults(results)
+}
+
+func decodeResults(yamlStr string) (Result, error) {
+	var ret Result
+
+	err := yaml.Unmarshal([]byte(yamlStr), &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
+func encodeResults(results Result) (string, error) {
 	d, err := json.Marshal(&results)
 	if err != nil {
 		return "", err
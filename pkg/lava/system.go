@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"fmt"
+)
+
+// SystemVersion returns the LAVA version of the server
+func (c Connection) SystemVersion() (string, error) {
+	return c.SystemVersionContext(context.Background())
+}
+
+// SystemVersionContext is like SystemVersion but uses ctx for the request
+func (c Connection) SystemVersionContext(ctx context.Context) (string, error) {
+	var ret string
+
+	err := c.call(ctx, "system.version", nil, &ret)
+	if err != nil {
+		return "", err
+	}
+
+	return ret, nil
+}
+
+// SystemAPIVersion returns the version of the XMLRPC API
+func (c Connection) SystemAPIVersion() (int, error) {
+	return c.SystemAPIVersionContext(context.Background())
+}
+
+// SystemAPIVersionContext is like SystemAPIVersion but uses ctx for the request
+func (c Connection) SystemAPIVersionContext(ctx context.Context) (int, error) {
+	var ret int
+
+	err := c.call(ctx, "system.api_version", nil, &ret)
+	if err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+// SystemWhoami returns the name of the authenticated user
+func (c Connection) SystemWhoami() (string, error) {
+	return c.SystemWhoamiContext(context.Background())
+}
+
+// SystemWhoamiContext is like SystemWhoami but uses ctx for the request
+func (c Connection) SystemWhoamiContext(ctx context.Context) (string, error) {
+	var ret string
+
+	err := c.call(ctx, "system.whoami", nil, &ret)
+	if err != nil {
+		return "", err
+	}
+
+	return ret, nil
+}
+
+// SystemListMethods returns the names of all methods available on the server
+func (c Connection) SystemListMethods() ([]string, error) {
+	return c.SystemListMethodsContext(context.Background())
+}
+
+// SystemListMethodsContext is like SystemListMethods but uses ctx for the request
+func (c Connection) SystemListMethodsContext(ctx context.Context) ([]string, error) {
+	var ret []string
+
+	err := c.call(ctx, "system.listMethods", nil, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SystemMethodHelp returns the documentation of the specified method
+func (c Connection) SystemMethodHelp(name string) (string, error) {
+	return c.SystemMethodHelpContext(context.Background(), name)
+}
+
+// SystemMethodHelpContext is like SystemMethodHelp but uses ctx for the request
+func (c Connection) SystemMethodHelpContext(ctx context.Context, name string) (string, error) {
+	var ret string
+
+	err := c.call(ctx, "system.methodHelp", name, &ret)
+	if err != nil {
+		return "", err
+	}
+
+	return ret, nil
+}
+
+// SystemMethodSignature returns the signatures of the specified method.
+// Each signature starts with the return type followed by the argument types.
+// Returns an empty list if the signature is undefined.
+func (c Connection) SystemMethodSignature(name string) ([][]string, error) {
+	return c.SystemMethodSignatureContext(context.Background(), name)
+}
+
+// SystemMethodSignatureContext is like SystemMethodSignature but uses ctx for the request
+func (c Connection) SystemMethodSignatureContext(ctx context.Context, name string) ([][]string, error) {
+	var ret [][]string
+	var xmlRet interface{}
+
+	err := c.call(ctx, "system.methodSignature", name, &xmlRet)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := xmlRet.(type) {
+	case string:
+		// "undef"
+	case []interface{}:
+		for i := range x {
+			types, ok := x[i].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Invalid server response")
+			}
+			var sig []string
+			for j := range types {
+				t, ok := types[j].(string)
+				if !ok {
+					return nil, fmt.Errorf("Invalid server response")
+				}
+				sig = append(sig, t)
+			}
+			ret = append(ret, sig)
+		}
+	default:
+		return nil, fmt.Errorf("Got unexpected type: %T", x)
+	}
+
+	return ret, nil
+}
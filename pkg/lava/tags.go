@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import "context"
+
+// Tag represents data as returned by LAVA XMLRPC scheduler.tags.list
+type Tag struct {
+	Name        string `xmlrpc:"name" json:"name" yaml:"name"`
+	Description string `xmlrpc:"description" json:"description" yaml:"description"`
+}
+
+// TagDetail represents data as returned by LAVA XMLRPC scheduler.tags.show
+type TagDetail struct {
+	Name        string   `xmlrpc:"name" json:"name" yaml:"name"`
+	Description string   `xmlrpc:"description" json:"description" yaml:"description"`
+	Devices     []string `xmlrpc:"devices" json:"devices" yaml:"devices"`
+}
+
+func (c Connection) TagsList() ([]Tag, error) {
+	return c.TagsListContext(context.Background())
+}
+
+// TagsListContext is like TagsList but uses ctx for the request
+func (c Connection) TagsListContext(ctx context.Context) ([]Tag, error) {
+	var ret []Tag
+
+	err := c.call(ctx, "scheduler.tags.list", nil, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c Connection) TagsShow(name string) (*TagDetail, error) {
+	return c.TagsShowContext(context.Background(), name)
+}
+
+// TagsShowContext is like TagsShow but uses ctx for the request
+func (c Connection) TagsShowContext(ctx context.Context, name string) (*TagDetail, error) {
+	var ret TagDetail
+
+	err := c.call(ctx, "scheduler.tags.show", name, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (c Connection) TagsAdd(name string, description string) error {
+	return c.TagsAddContext(context.Background(), name, description)
+}
+
+// TagsAddContext is like TagsAdd but uses ctx for the request
+func (c Connection) TagsAddContext(ctx context.Context, name string, description string) error {
+	var args []interface{}
+	args = append(args, name)
+	args = append(args, optional(description))
+
+	return c.call(ctx, "scheduler.tags.add", trimNone(args), nil)
+}
+
+func (c Connection) TagsDelete(name string) error {
+	return c.TagsDeleteContext(context.Background(), name)
+}
+
+// TagsDeleteContext is like TagsDelete but uses ctx for the request
+func (c Connection) TagsDeleteContext(ctx context.Context, name string) error {
+	return c.call(ctx, "scheduler.tags.delete", name, nil)
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"time"
+)
+
+// User represents a single entry as returned by LAVA XMLRPC auth.users.list
+type User struct {
+	Username    string `xmlrpc:"username" json:"username" yaml:"username"`
+	FirstName   string `xmlrpc:"first_name" json:"first_name" yaml:"first_name"`
+	LastName    string `xmlrpc:"last_name" json:"last_name" yaml:"last_name"`
+	IsActive    bool   `xmlrpc:"is_active" json:"is_active" yaml:"is_active"`
+	IsStaff     bool   `xmlrpc:"is_staff" json:"is_staff" yaml:"is_staff"`
+	IsSuperuser bool   `xmlrpc:"is_superuser" json:"is_superuser" yaml:"is_superuser"`
+}
+
+// UserDetail represents data as returned by LAVA XMLRPC auth.users.show
+type UserDetail struct {
+	ID          int       `xmlrpc:"id" json:"id" yaml:"id"`
+	Username    string    `xmlrpc:"username" json:"username" yaml:"username"`
+	Email       string    `xmlrpc:"email" json:"email" yaml:"email"`
+	FirstName   string    `xmlrpc:"first_name" json:"first_name" yaml:"first_name"`
+	LastName    string    `xmlrpc:"last_name" json:"last_name" yaml:"last_name"`
+	IsActive    bool      `xmlrpc:"is_active" json:"is_active" yaml:"is_active"`
+	IsStaff     bool      `xmlrpc:"is_staff" json:"is_staff" yaml:"is_staff"`
+	IsSuperuser bool      `xmlrpc:"is_superuser" json:"is_superuser" yaml:"is_superuser"`
+	Groups      []string  `xmlrpc:"groups" json:"groups" yaml:"groups"`
+	Permissions []string  `xmlrpc:"permissions" json:"permissions" yaml:"permissions"`
+	LastLogin   time.Time `xmlrpc:"last_login" json:"last_login" yaml:"last_login"`
+	DateJoined  time.Time `xmlrpc:"date_joined" json:"date_joined" yaml:"date_joined"`
+}
+
+// UserSettings holds the user properties passed to LAVA XMLRPC
+// auth.users.add and auth.users.update.
+// Empty or nil fields are left unchanged on update.
+type UserSettings struct {
+	FirstName   string
+	LastName    string
+	Email       string
+	IsActive    *bool
+	IsStaff     *bool
+	IsSuperuser *bool
+}
+
+func (c Connection) UsersList() ([]User, error) {
+	return c.UsersListContext(context.Background())
+}
+
+// UsersListContext is like UsersList but uses ctx for the request
+func (c Connection) UsersListContext(ctx context.Context) ([]User, error) {
+	var ret []User
+
+	err := c.call(ctx, "auth.users.list", nil, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c Connection) UsersShow(username string) (*UserDetail, error) {
+	return c.UsersShowContext(context.Background(), username)
+}
+
+// UsersShowContext is like UsersShow but uses ctx for the request
+func (c Connection) UsersShowContext(ctx context.Context, username string) (*UserDetail, error) {
+	var ret UserDetail
+
+	err := c.call(ctx, "auth.users.show", username, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UsersAdd adds a new user. The user is active, but neither staff nor
+// superuser unless specified otherwise.
+func (c Connection) UsersAdd(username string, s UserSettings) error {
+	return c.UsersAddContext(context.Background(), username, s)
+}
+
+// UsersAddContext is like UsersAdd but uses ctx for the request
+func (c Connection) UsersAddContext(ctx context.Context, username string, s UserSettings) error {
+	var args []interface{}
+	args = append(args, username)
+	// The server defaults to empty strings, older servers reject nil
+	args = append(args, s.FirstName)
+	args = append(args, s.LastName)
+	args = append(args, s.Email)
+	args = append(args, optionalBool(s.IsActive))
+	args = append(args, optionalBool(s.IsStaff))
+	args = append(args, optionalBool(s.IsSuperuser))
+
+	return c.call(ctx, "auth.users.add", trimNone(args), nil)
+}
+
+// UsersUpdate updates the properties of an existing user
+func (c Connection) UsersUpdate(username string, s UserSettings) error {
+	return c.UsersUpdateContext(context.Background(), username, s)
+}
+
+// UsersUpdateContext is like UsersUpdate but uses ctx for the request
+func (c Connection) UsersUpdateContext(ctx context.Context, username string, s UserSettings) error {
+	var args []interface{}
+	args = append(args, username)
+	args = append(args, optional(s.FirstName))
+	args = append(args, optional(s.LastName))
+	args = append(args, optional(s.Email))
+	args = append(args, optionalBool(s.IsActive))
+	args = append(args, optionalBool(s.IsStaff))
+	args = append(args, optionalBool(s.IsSuperuser))
+
+	return c.call(ctx, "auth.users.update", trimNone(args), nil)
+}
+
+func (c Connection) UsersDelete(username string) error {
+	return c.UsersDeleteContext(context.Background(), username)
+}
+
+// UsersDeleteContext is like UsersDelete but uses ctx for the request
+func (c Connection) UsersDeleteContext(ctx context.Context, username string) error {
+	return c.call(ctx, "auth.users.delete", username, nil)
+}
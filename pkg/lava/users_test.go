@@ -0,0 +1,34 @@
+package lava
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConnection_UsersAdd(t *testing.T) {
+	var req string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req = string(body)
+		w.Write(xmlrpcResponse(`<value><nil/></value>`))
+	}))
+	defer srv.Close()
+
+	c, err := ConnectByURI(srv.URL+"/RPC2", "", ConnectionOptions{Transport: &http.Transport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staff := true
+	err = c.UsersAdd("bob", UserSettings{Email: "bob@example.com", IsStaff: &staff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unset names are empty strings, only unset booleans are nil
+	if strings.Count(req, "<string></string>") != 2 || strings.Count(req, "<nil/>") != 1 ||
+		!strings.Contains(req, "<boolean>1</boolean>") {
+		t.Errorf("unexpected request %s", req)
+	}
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// websocketMaxMessage limits the size of a single message
+const websocketMaxMessage = 16 << 20
+
+// websocketCloseTimeout limits the time spent sending the close message
+const websocketCloseTimeout = time.Second
+
+// wsConn receives messages from a websocket. Pings and close messages of the
+// server are answered by github.com/gorilla/websocket.
+type wsConn struct {
+	conn *websocket.Conn
+}
+
+// dialWebsocket connects to a ws:// or wss:// URI.
+// ctx only applies to the connection setup.
+func dialWebsocket(ctx context.Context, uri string, tlsConfig *tls.Config) (*wsConn, error) {
+	// Abort the handshake once ctx is done
+	var mu sync.Mutex
+	var conn net.Conn
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			if conn != nil {
+				conn.Close()
+			}
+			mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	d := websocket.Dialer{
+		TLSClientConfig: tlsConfig,
+		NetDial: func(network string, addr string) (net.Conn, error) {
+			var nd net.Dialer
+			c, err := nd.DialContext(ctx, network, addr)
+			mu.Lock()
+			conn = c
+			mu.Unlock()
+			return c, err
+		},
+	}
+	ws, resp, err := d.Dial(uri, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == websocket.ErrBadHandshake && resp != nil {
+			return nil, fmt.Errorf("Websocket handshake failed: %s", resp.Status)
+		}
+		return nil, err
+	}
+	ws.SetReadLimit(websocketMaxMessage)
+
+	return &wsConn{conn: ws}, nil
+}
+
+// readMessage returns the next text or binary message.
+// Returns io.EOF once the server closed the connection.
+func (ws *wsConn) readMessage() ([]byte, error) {
+	_, msg, err := ws.conn.ReadMessage()
+	if _, ok := err.(*websocket.CloseError); ok {
+		return nil, io.EOF
+	}
+	return msg, err
+}
+
+// Close sends a close message and closes the connection. It's safe to call
+// Close while another go routine is blocked in readMessage.
+func (ws *wsConn) Close() error {
+	ws.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(websocketCloseTimeout))
+	return ws.conn.Close()
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: BSD-3-Clause
+
+package lava
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+)
+
+// Worker represents a single entry as returned by LAVA XMLRPC scheduler.workers.list
+type Worker struct {
+	Hostname string `xmlrpc:"hostname" json:"hostname" yaml:"hostname"`
+}
+
+// WorkerDetail represents data as returned by LAVA XMLRPC scheduler.workers.show
+type WorkerDetail struct {
+	Hostname      string    `xmlrpc:"hostname" json:"hostname" yaml:"hostname"`
+	Description   string    `xmlrpc:"description" json:"description" yaml:"description"`
+	State         string    `xmlrpc:"state" json:"state" yaml:"state"`
+	Health        string    `xmlrpc:"health" json:"health" yaml:"health"`
+	Devices       []string  `xmlrpc:"devices" json:"devices" yaml:"devices"`
+	LastPing      time.Time `xmlrpc:"last_ping" json:"last_ping" yaml:"last_ping"`
+	JobLimit      int       `xmlrpc:"job_limit" json:"job_limit" yaml:"job_limit"`
+	Version       string    `xmlrpc:"version" json:"version" yaml:"version"`
+	DefaultConfig bool      `xmlrpc:"default_config" json:"default_config" yaml:"default_config"`
+	DefaultEnv    bool      `xmlrpc:"default_env" json:"default_env" yaml:"default_env"`
+}
+
+func (c Connection) WorkersList() ([]Worker, error) {
+	return c.WorkersListContext(context.Background())
+}
+
+// WorkersListContext is like WorkersList but uses ctx for the request
+func (c Connection) WorkersListContext(ctx context.Context) ([]Worker, error) {
+	var names []string
+	var ret []Worker
+
+	err := c.call(ctx, "scheduler.workers.list", nil, &names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range names {
+		ret = append(ret, Worker{Hostname: n})
+	}
+
+	return ret, nil
+}
+
+func (c Connection) WorkersShow(hostname string) (*WorkerDetail, error) {
+	return c.WorkersShowContext(context.Background(), hostname)
+}
+
+// WorkersShowContext is like WorkersShow but uses ctx for the request
+func (c Connection) WorkersShowContext(ctx context.Context, hostname string) (*WorkerDetail, error) {
+	var ret WorkerDetail
+
+	err := c.call(ctx, "scheduler.workers.show", hostname, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (c Connection) WorkersAdd(hostname string, description string, disabled bool) error {
+	return c.WorkersAddContext(context.Background(), hostname, description, disabled)
+}
+
+// WorkersAddContext is like WorkersAdd but uses ctx for the request
+func (c Connection) WorkersAddContext(ctx context.Context, hostname string, description string, disabled bool) error {
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, description)
+	args = append(args, disabled)
+
+	return c.call(ctx, "scheduler.workers.add", args, nil)
+}
+
+// WorkersUpdate sets the description and health of a worker.
+// Valid health values are ACTIVE, MAINTENANCE and RETIRED.
+func (c Connection) WorkersUpdate(hostname string, description string, health string) error {
+	return c.WorkersUpdateContext(context.Background(), hostname, description, health)
+}
+
+// WorkersUpdateContext is like WorkersUpdate but uses ctx for the request
+func (c Connection) WorkersUpdateContext(ctx context.Context, hostname string, description string, health string) error {
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, description)
+	args = append(args, health)
+
+	return c.call(ctx, "scheduler.workers.update", args, nil)
+}
+
+func (c Connection) WorkersConfigGet(hostname string) (string, error) {
+	return c.WorkersConfigGetContext(context.Background(), hostname)
+}
+
+// WorkersConfigGetContext is like WorkersConfigGet but uses ctx for the request
+func (c Connection) WorkersConfigGetContext(ctx context.Context, hostname string) (string, error) {
+	var data string
+
+	err := c.call(ctx, "scheduler.workers.get_config", hostname, &data)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+func (c Connection) WorkersConfigSet(hostname string, config string) error {
+	return c.WorkersConfigSetContext(context.Background(), hostname, config)
+}
+
+// WorkersConfigSetContext is like WorkersConfigSet but uses ctx for the request
+func (c Connection) WorkersConfigSetContext(ctx context.Context, hostname string, config string) error {
+	var ret bool
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, config)
+
+	return c.call(ctx, "scheduler.workers.set_config", args, &ret)
+}
+
+func (c Connection) WorkersEnvGet(hostname string) (string, error) {
+	return c.WorkersEnvGetContext(context.Background(), hostname)
+}
+
+// WorkersEnvGetContext is like WorkersEnvGet but uses ctx for the request
+func (c Connection) WorkersEnvGetContext(ctx context.Context, hostname string) (string, error) {
+	var data string
+
+	err := c.call(ctx, "scheduler.workers.get_env", hostname, &data)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+func (c Connection) WorkersEnvSet(hostname string, env string) error {
+	return c.WorkersEnvSetContext(context.Background(), hostname, env)
+}
+
+// WorkersEnvSetContext is like WorkersEnvSet but uses ctx for the request
+func (c Connection) WorkersEnvSetContext(ctx context.Context, hostname string, env string) error {
+	var ret bool
+	var args []interface{}
+	args = append(args, hostname)
+	args = append(args, env)
+
+	return c.call(ctx, "scheduler.workers.set_env", args, &ret)
+}
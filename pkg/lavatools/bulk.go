@@ -0,0 +1,262 @@
+package lavatools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+)
+
+// maxRangeJobs limits the number of jobs a single IDRange can select
+const maxRangeJobs = 10000
+
+// IDRange is an inclusive range of job IDs
+type IDRange struct {
+	From int
+	To   int
+}
+
+// ParseIDRange parses a single job ID "42" or an inclusive range "40-50"
+func ParseIDRange(s string) (r IDRange, err error) {
+	parts := strings.SplitN(s, "-", 2)
+	r.From, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		err = fmt.Errorf("Invalid job ID range %s", s)
+		return
+	}
+	r.To = r.From
+	if len(parts) == 2 {
+		r.To, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			err = fmt.Errorf("Invalid job ID range %s", s)
+			return
+		}
+	}
+	if r.To < r.From {
+		err = fmt.Errorf("Invalid job ID range %s", s)
+		return
+	}
+	if r.To-r.From >= maxRangeJobs {
+		err = fmt.Errorf("Job ID range %s is too big", s)
+	}
+
+	return
+}
+
+// JobSelector selects jobs for bulk operations. Empty fields match all jobs.
+type JobSelector struct {
+	lava.JobsFilter
+	// OlderThan only matches jobs submitted at least OlderThan ago
+	OlderThan time.Duration
+	// IDs only matches jobs within the ranges
+	IDs []IDRange
+}
+
+// Match returns true if the job matches all criteria of the selector
+func (s JobSelector) Match(job lava.JobsListing) bool {
+	if !s.JobsFilter.Match(job) {
+		return false
+	}
+	if s.OlderThan > 0 && time.Since(job.SubmitTime) < s.OlderThan {
+		return false
+	}
+	if len(s.IDs) > 0 {
+		for _, r := range s.IDs {
+			if job.ID >= r.From && job.ID <= r.To {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+//SelectJobs returns all jobs matching the selector. Jobs selected by ID are
+//looked up using parallel go routines. Jobs failing to be looked up are
+//returned in failed. Missing jobs are only reported if selected by a single
+//ID, the gaps of ID ranges are skipped. Without IDs, one of State, Health or
+//Submitter is required.
+func (con lt) SelectJobs(sel JobSelector, parallel int) (list []lava.JobsListing, failed []JobOperationResult, err error) {
+	if len(sel.IDs) > 0 {
+		list, failed = con.selectJobsByID(sel, parallel)
+		return
+	}
+	// Otherwise every bulk operation walks the whole job history
+	if sel.State == "" && sel.Health == "" && sel.Submitter == "" {
+		err = fmt.Errorf("Selecting jobs requires IDs, a state, a health or a submitter")
+		return
+	}
+
+	var page []lava.JobsListing
+	page, err = con.QueryJobListFilteredWithRetry(sel.JobsFilter, 0, 0)
+	if err != nil {
+		return
+	}
+
+	// The XMLRPC job list doesn't return the submit time
+	var ids []int
+	for _, j := range page {
+		if sel.OlderThan > 0 && j.SubmitTime.IsZero() {
+			ids = append(ids, j.ID)
+		}
+	}
+	jobs, failed := con.showJobs(ids, parallel)
+	failed = skipNotFound(failed)
+	for _, j := range page {
+		if sel.OlderThan > 0 && j.SubmitTime.IsZero() {
+			state, ok := jobs[j.ID]
+			if !ok {
+				continue
+			}
+			j.SubmitTime = state.SubmitTime
+		}
+		if sel.Match(j) {
+			list = append(list, j)
+		}
+	}
+
+	return
+}
+
+//showJobs looks up the jobs using parallel go routines. Jobs failing to be
+//looked up are returned in failed.
+func (con lt) showJobs(ids []int, parallel int) (jobs map[int]lava.JobsListing, failed []JobOperationResult) {
+	// Every go routine writes its own element
+	states := make([]*lava.JobState, len(ids))
+	index := make(map[int]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	results := bulk(con.ctx, ids, parallel, func(id int) ([]int, error) {
+		state, err := con.c.JobsShowContext(con.ctx, id)
+		states[index[id]] = state
+		return nil, err
+	})
+
+	jobs = map[int]lava.JobsListing{}
+	for i, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+			continue
+		}
+		state := states[i]
+		jobs[r.ID] = lava.JobsListing{
+			Description: state.Description,
+			DeviceType:  state.DeviceType,
+			Health:      state.Health,
+			ID:          state.ID,
+			State:       state.State,
+			Submitter:   state.Submitter,
+			SubmitTime:  state.SubmitTime,
+		}
+	}
+
+	return
+}
+
+//skipNotFound drops the results of jobs which don't exist
+func skipNotFound(results []JobOperationResult) (ret []JobOperationResult) {
+	for _, r := range results {
+		if !errors.Is(r.Err, lava.ErrNotFound) {
+			ret = append(ret, r)
+		}
+	}
+	return
+}
+
+//selectJobsByID looks up every job in the selector's ID ranges, which is
+//much faster than walking the whole job list
+func (con lt) selectJobsByID(sel JobSelector, parallel int) (list []lava.JobsListing, failed []JobOperationResult) {
+	var ids []int
+	seen := map[int]bool{}
+	single := map[int]bool{}
+	for _, r := range sel.IDs {
+		if r.From == r.To {
+			single[r.From] = true
+		}
+		for id := r.From; id <= r.To; id++ {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	jobs, results := con.showJobs(ids, parallel)
+	for _, r := range results {
+		if single[r.ID] || !errors.Is(r.Err, lava.ErrNotFound) {
+			failed = append(failed, r)
+		}
+	}
+	for _, id := range ids {
+		if j, ok := jobs[id]; ok && sel.Match(j) {
+			list = append(list, j)
+		}
+	}
+
+	return
+}
+
+// JobOperationResult is the outcome of a bulk operation on a single job
+type JobOperationResult struct {
+	ID int
+	// NewIDs holds the new job IDs of resubmitted jobs
+	NewIDs []int
+	Err    error
+}
+
+//bulk runs op on all jobs using parallel go routines. Once ctx is done
+//the remaining jobs aren't touched and report the context's error.
+func bulk(ctx context.Context, ids []int, parallel int, op func(id int) ([]int, error)) []JobOperationResult {
+	ret := make([]JobOperationResult, len(ids))
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for i := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ret[i].ID = ids[i]
+			if ctx.Err() != nil {
+				ret[i].Err = ctx.Err()
+				return
+			}
+			ret[i].NewIDs, ret[i].Err = op(ids[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return ret
+}
+
+//CancelJobs cancels all jobs using parallel go routines
+func (con lt) CancelJobs(ids []int, parallel int) []JobOperationResult {
+	return bulk(con.ctx, ids, parallel, func(id int) ([]int, error) {
+		return nil, con.c.JobsCancelContext(con.ctx, id)
+	})
+}
+
+//FailJobs fails all jobs using parallel go routines
+func (con lt) FailJobs(ids []int, parallel int) []JobOperationResult {
+	return bulk(con.ctx, ids, parallel, func(id int) ([]int, error) {
+		return nil, con.c.JobsFailContext(con.ctx, id)
+	})
+}
+
+//ResubmitJobs resubmits all jobs using parallel go routines
+func (con lt) ResubmitJobs(ids []int, parallel int) []JobOperationResult {
+	return bulk(con.ctx, ids, parallel, func(id int) ([]int, error) {
+		return con.c.JobsResubmitContext(con.ctx, id)
+	})
+}
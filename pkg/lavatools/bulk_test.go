@@ -0,0 +1,191 @@
+package lavatools
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lava/lavafake"
+)
+
+func TestParseIDRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    IDRange
+		wantErr bool
+	}{
+		{"42", IDRange{42, 42}, false},
+		{"40-50", IDRange{40, 50}, false},
+		{" 40 - 50 ", IDRange{40, 50}, false},
+		{"50-40", IDRange{}, true},
+		{"abc", IDRange{}, true},
+		{"1-", IDRange{}, true},
+		{"1-100000", IDRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIDRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIDRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseIDRange(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJobSelector_Match(t *testing.T) {
+	job := lava.JobsListing{
+		ID:          42,
+		State:       "Submitted",
+		Health:      "Unknown",
+		Submitter:   "alice",
+		DeviceType:  "qemu",
+		Description: "nightly boot test",
+		SubmitTime:  time.Now().Add(-time.Hour * 2),
+	}
+	tests := []struct {
+		name string
+		sel  JobSelector
+		want bool
+	}{
+		{"empty", JobSelector{}, true},
+		{"state", JobSelector{JobsFilter: lava.JobsFilter{State: "SUBMITTED"}}, true},
+		{"wrong state", JobSelector{JobsFilter: lava.JobsFilter{State: "RUNNING"}}, false},
+		{"health", JobSelector{JobsFilter: lava.JobsFilter{Health: "UNKNOWN"}}, true},
+		{"submitter", JobSelector{JobsFilter: lava.JobsFilter{Submitter: "alice"}}, true},
+		{"wrong submitter", JobSelector{JobsFilter: lava.JobsFilter{Submitter: "bob"}}, false},
+		{"device-type", JobSelector{JobsFilter: lava.JobsFilter{DeviceType: "qemu"}}, true},
+		{"wrong device-type", JobSelector{JobsFilter: lava.JobsFilter{DeviceType: "imx8"}}, false},
+		{"older than", JobSelector{OlderThan: time.Hour}, true},
+		{"too young", JobSelector{OlderThan: time.Hour * 3}, false},
+		{"submitted after", JobSelector{JobsFilter: lava.JobsFilter{SubmittedAfter: time.Now().Add(-time.Hour * 3)}}, true},
+		{"submitted too early", JobSelector{JobsFilter: lava.JobsFilter{SubmittedAfter: time.Now().Add(-time.Hour)}}, false},
+		{"submitted before", JobSelector{JobsFilter: lava.JobsFilter{SubmittedBefore: time.Now()}}, true},
+		{"submitted too late", JobSelector{JobsFilter: lava.JobsFilter{SubmittedBefore: time.Now().Add(-time.Hour * 3)}}, false},
+		{"description", JobSelector{JobsFilter: lava.JobsFilter{Description: regexp.MustCompile("^nightly")}}, true},
+		{"wrong description", JobSelector{JobsFilter: lava.JobsFilter{Description: regexp.MustCompile("^weekly")}}, false},
+		{"in range", JobSelector{IDs: []IDRange{{1, 10}, {40, 50}}}, true},
+		{"out of range", JobSelector{IDs: []IDRange{{1, 10}}}, false},
+		{"all", JobSelector{JobsFilter: lava.JobsFilter{State: "submitted", Submitter: "alice"}, IDs: []IDRange{{42, 42}}}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.sel.Match(job); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBulk_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	ret := bulk(ctx, []int{1, 2}, 2, func(id int) ([]int, error) {
+		called = true
+		return nil, nil
+	})
+	if called {
+		t.Errorf("bulk() ran op with a canceled context")
+	}
+	for _, r := range ret {
+		if r.Err != context.Canceled {
+			t.Errorf("bulk() job %d error = %v, want context.Canceled", r.ID, r.Err)
+		}
+	}
+}
+
+func TestSleep_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := sleep(ctx, time.Minute)
+	if err != context.Canceled {
+		t.Errorf("sleep() error = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("sleep() didn't return once the context was canceled")
+	}
+}
+
+func Test_lt_SelectJobs_gap(t *testing.T) {
+	f, con := newFakeTools(t)
+	for i := 0; i < 2; i++ {
+		if _, err := f.JobsSubmitString("device_type: qemu\njob_name: test\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Jobs 4 and 5 don't exist
+	list, failed, err := con.SelectJobs(JobSelector{IDs: []IDRange{{1, 5}}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].ID != 1 || list[1].ID != 2 || list[2].ID != 3 || len(failed) != 0 {
+		t.Errorf("SelectJobs() = %+v, failed %+v, want jobs 1 to 3", list, failed)
+	}
+	list, _, err = con.SelectJobs(JobSelector{JobsFilter: lava.JobsFilter{State: "RUNNING"}, IDs: []IDRange{{1, 5}}}, 2)
+	if err != nil || len(list) != 1 || list[0].ID != 1 {
+		t.Errorf("SelectJobs() = %+v, %v, want job 1", list, err)
+	}
+	// Missing jobs selected by a single ID are reported
+	list, failed, err = con.SelectJobs(JobSelector{IDs: []IDRange{{3, 3}, {4, 4}}}, 2)
+	if err != nil || len(list) != 1 || len(failed) != 1 || failed[0].ID != 4 || !errors.Is(failed[0].Err, lava.ErrNotFound) {
+		t.Errorf("SelectJobs() = %+v, failed %+v, %v, want job 3 and job 4 not found", list, failed, err)
+	}
+
+	f.SetError("JobsShow", &lava.PermissionDeniedError{Fault: lava.Fault{Code: 403, Message: "Permission denied"}})
+	list, failed, err = con.SelectJobs(JobSelector{IDs: []IDRange{{2, 3}}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 || len(failed) != 2 || failed[0].ID != 2 || !errors.Is(failed[1].Err, lava.ErrPermissionDenied) {
+		t.Errorf("SelectJobs() = %+v, failed %+v, want jobs 2 and 3 failed", list, failed)
+	}
+}
+
+// xmlrpcListing behaves like the XMLRPC job list, which doesn't return the submit time
+type xmlrpcListing struct {
+	*lavafake.Fake
+}
+
+func (x xmlrpcListing) JobsListFilteredContext(ctx context.Context, filter lava.JobsFilter, start int, limit int) ([]lava.JobsListing, error) {
+	list, err := x.Fake.JobsListFilteredContext(ctx, filter, start, limit)
+	for i := range list {
+		list[i].SubmitTime = time.Time{}
+	}
+	return list, err
+}
+
+func Test_lt_SelectJobs_olderThan(t *testing.T) {
+	f, _ := newFakeTools(t)
+	con, err := NewLavaTools(xmlrpcListing{f}, fakeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Job 1 has just been submitted
+	sel := JobSelector{JobsFilter: lava.JobsFilter{State: "RUNNING"}, OlderThan: time.Hour}
+	list, failed, err := con.SelectJobs(sel, 2)
+	if err != nil || len(list) != 0 || len(failed) != 0 {
+		t.Errorf("SelectJobs() = %+v, %+v, %v, want no jobs", list, failed, err)
+	}
+	if n := f.Calls("JobsShow"); n != 1 {
+		t.Errorf("JobsShow called %d times, want 1", n)
+	}
+
+	_, _, err = con.SelectJobs(JobSelector{OlderThan: time.Hour}, 2)
+	if err == nil {
+		t.Errorf("SelectJobs() expected error without state, health or submitter")
+	}
+
+	sel.OlderThan = time.Nanosecond
+	list, _, err = con.SelectJobs(sel, 2)
+	if err != nil || len(list) != 1 || list[0].ID != 1 || list[0].SubmitTime.IsZero() {
+		t.Errorf("SelectJobs() = %+v, %v, want job 1", list, err)
+	}
+}
@@ -1,6 +1,8 @@
 package lavatools
 
 import (
+	"context"
+
 	"github.com/siro20/lavacli/pkg/lava"
 
 	"time"
@@ -28,7 +30,7 @@ type deviceTypeTemplateCache struct {
 
 //cache implements a caching layer for lavatools
 type cache struct {
-	c              *lava.Connection
+	c              lava.Client
 	pollInterval   time.Duration
 	invalidTimeout time.Duration
 	retry          *retry
@@ -39,11 +41,11 @@ type cache struct {
 	deviceTypeTemplate map[string]deviceTypeTemplateCache
 }
 
-func (c *cache) updateDeviceTagsList(name string) (err error) {
+func (c *cache) updateDeviceTagsList(ctx context.Context, name string) (err error) {
 	var l []string
 	tag, ok := c.deviceTags[name]
 	if !ok || time.Now().Sub(tag.Timestamp) > c.pollInterval {
-		l, err = c.retry.GetDeviceTagsList(name)
+		l, err = c.retry.GetDeviceTagsList(ctx, name)
 		if err == nil {
 			c.deviceTags[name] = tagsCache{Tags: l, Timestamp: time.Now()}
 		} else if time.Now().Sub(tag.Timestamp) > c.invalidTimeout {
@@ -55,11 +57,11 @@ func (c *cache) updateDeviceTagsList(name string) (err error) {
 	return
 }
 
-func (c *cache) updateDeviceTypeTemplates(name string) (err error) {
+func (c *cache) updateDeviceTypeTemplates(ctx context.Context, name string) (err error) {
 	var s string
 	template, ok := c.deviceTypeTemplate[name]
 	if !ok || time.Now().Sub(template.Timestamp) > c.pollInterval || len(c.deviceTypeTemplate) == 0 {
-		s, err = c.retry.GetDeviceTypeTemplates(name)
+		s, err = c.retry.GetDeviceTypeTemplates(ctx, name)
 		if err == nil {
 			c.deviceTypeTemplate[name] = deviceTypeTemplateCache{Template: s, Timestamp: time.Now()}
 		} else if time.Now().Sub(template.Timestamp) > c.invalidTimeout {
@@ -71,10 +73,10 @@ func (c *cache) updateDeviceTypeTemplates(name string) (err error) {
 	return
 }
 
-func (c *cache) updateDeviceList() (err error) {
+func (c *cache) updateDeviceList(ctx context.Context) (err error) {
 	var l []lava.DeviceList
 	if time.Now().Sub(c.deviceList.Timestamp) > c.pollInterval || len(c.deviceList.DeviceList) == 0 {
-		l, err = c.retry.GetDeviceList()
+		l, err = c.retry.GetDeviceList(ctx)
 		if err == nil {
 			c.deviceList.Timestamp = time.Now()
 			c.deviceList.DeviceList = l
@@ -87,11 +89,11 @@ func (c *cache) updateDeviceList() (err error) {
 	return
 }
 
-func (c *cache) updateDevice(name string) (err error) {
+func (c *cache) updateDevice(ctx context.Context, name string) (err error) {
 	var d *lava.Device
 	dev, ok := c.devices[name]
 	if !ok || time.Now().Sub(dev.Timestamp) > c.pollInterval || len(c.devices) == 0 {
-		d, err = c.retry.GetDevice(name)
+		d, err = c.retry.GetDevice(ctx, name)
 		if err == nil {
 			c.devices[name] = deviceCache{Device: *d, Timestamp: time.Now()}
 		} else if time.Now().Sub(dev.Timestamp) > c.invalidTimeout {
@@ -105,8 +107,8 @@ func (c *cache) updateDevice(name string) (err error) {
 
 // GetDeviceList returns a cached version of the DeviceList if withing time boundaries
 // or returns an error if retrieving new data fails for too long
-func (c *cache) GetDeviceList() (devList []lava.DeviceList, err error) {
-	err = c.updateDeviceList()
+func (c *cache) GetDeviceList(ctx context.Context) (devList []lava.DeviceList, err error) {
+	err = c.updateDeviceList(ctx)
 	devList = c.deviceList.DeviceList
 
 	return
@@ -114,8 +116,8 @@ func (c *cache) GetDeviceList() (devList []lava.DeviceList, err error) {
 
 // GetDevice returns a cached version of the DeviceShow if withing time boundaries
 // or returns an error if retrieving new data fails for too long
-func (c *cache) GetDevice(name string) (dev lava.Device, err error) {
-	err = c.updateDevice(name)
+func (c *cache) GetDevice(ctx context.Context, name string) (dev lava.Device, err error) {
+	err = c.updateDevice(ctx, name)
 	dev = c.devices[name].Device
 
 	return
@@ -123,8 +125,8 @@ func (c *cache) GetDevice(name string) (dev lava.Device, err error) {
 
 // GetDeviceTypeTemplates returns a cached version of the DeviceTypeTemplateGet if withing time boundaries
 // or returns an error if retrieving new data fails for too long
-func (c *cache) GetDeviceTypeTemplates(name string) (template string, err error) {
-	err = c.updateDeviceTypeTemplates(name)
+func (c *cache) GetDeviceTypeTemplates(ctx context.Context, name string) (template string, err error) {
+	err = c.updateDeviceTypeTemplates(ctx, name)
 	template = c.deviceTypeTemplate[name].Template
 
 	return
@@ -132,8 +134,8 @@ func (c *cache) GetDeviceTypeTemplates(name string) (template string, err error)
 
 // GetDeviceTagsList returns a cached version of the DeviceTagList if withing time boundaries
 // or returns an error if retrieving new data fails for too long
-func (c *cache) GetDeviceTagsList(name string) (tags []string, err error) {
-	err = c.updateDeviceTagsList(name)
+func (c *cache) GetDeviceTagsList(ctx context.Context, name string) (tags []string, err error) {
+	err = c.updateDeviceTagsList(ctx, name)
 	tags = c.deviceTags[name].Tags
 
 	return
@@ -143,16 +145,17 @@ func (c *cache) GetDeviceTagsList(name string) (tags []string, err error) {
 func (c *cache) updatePeriodic(timeout time.Duration) {
 	time.Sleep(timeout)
 	time.Sleep(1)
-	c.updateDeviceList()
+	ctx := context.Background()
+	c.updateDeviceList(ctx)
 	for i := range c.deviceList.DeviceList {
-		c.updateDeviceTagsList(c.deviceList.DeviceList[i].Hostname)
-		c.updateDevice(c.deviceList.DeviceList[i].Hostname)
+		c.updateDeviceTagsList(ctx, c.deviceList.DeviceList[i].Hostname)
+		c.updateDevice(ctx, c.deviceList.DeviceList[i].Hostname)
 	}
 	go c.updatePeriodic(timeout)
 }
 
 //newLavaToolsCache returns a cache object
-func newLavaToolsCache(c *lava.Connection, retry *retry, opt Options) (obj *cache, err error) {
+func newLavaToolsCache(c lava.Client, retry *retry, opt Options) (obj *cache, err error) {
 	obj = &cache{c: c,
 		deviceList:         deviceListCache{DeviceList: []lava.DeviceList{}},
 		devices:            map[string]deviceCache{},
@@ -1,7 +1,9 @@
 package lavatools
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/siro20/lavacli/pkg/lava"
 )
@@ -10,7 +12,7 @@ import (
 // Retries to get the list in case of error
 func (con lt) DeviceListWithRetry() (devList []lava.DeviceList, err error) {
 
-	devList, err = con.retry.GetDeviceList()
+	devList, err = con.retry.GetDeviceList(con.ctx)
 
 	return
 }
@@ -19,7 +21,7 @@ func (con lt) DeviceListWithRetry() (devList []lava.DeviceList, err error) {
 // Retries to get the list in case of error
 func (con lt) DevicesTagsListWithRetry(name string) (ret []string, err error) {
 
-	ret, err = con.retry.GetDeviceTagsList(name)
+	ret, err = con.retry.GetDeviceTagsList(con.ctx, name)
 
 	return
 }
@@ -27,7 +29,7 @@ func (con lt) DevicesTagsListWithRetry(name string) (ret []string, err error) {
 //DevicesShowCached caches the device to show as every API call takes a while
 func (con lt) DevicesShowCached(name string) (dev lava.Device, err error) {
 
-	dev, err = con.cache.GetDevice(name)
+	dev, err = con.cache.GetDevice(con.ctx, name)
 
 	return
 }
@@ -35,7 +37,7 @@ func (con lt) DevicesShowCached(name string) (dev lava.Device, err error) {
 //DeviceListCached caches the device list as every API call takes a while
 func (con lt) DeviceListCached() (devList []lava.DeviceList, err error) {
 
-	devList, err = con.cache.GetDeviceList()
+	devList, err = con.cache.GetDeviceList(con.ctx)
 
 	return
 }
@@ -45,7 +47,7 @@ func (con lt) DeviceListHealthyCached() (devListHealthy []lava.DeviceList, err e
 	var devList []lava.DeviceList
 	devListHealthy = []lava.DeviceList{}
 
-	devList, err = con.cache.GetDeviceList()
+	devList, err = con.cache.GetDeviceList(con.ctx)
 	if err != nil {
 		return
 	}
@@ -61,7 +63,7 @@ func (con lt) DeviceListHealthyCached() (devListHealthy []lava.DeviceList, err e
 //DevicesTagsListCached caches the device tags as every API call takes a while
 func (con lt) DevicesTagsListCached(name string) (tags []string, err error) {
 
-	tags, err = con.cache.GetDeviceTagsList(name)
+	tags, err = con.cache.GetDeviceTagsList(con.ctx, name)
 
 	return
 }
@@ -155,3 +157,55 @@ func (con lt) DeviceOfTypeIsAliveAndHasTagCached(deviceType string, tagsToMatch
 
 	return
 }
+
+//DeviceMaintenance sets the device health to MAINTENANCE, which prevents
+//new jobs from being scheduled. Depending on opt it waits for the current
+//job to finish or cancels it.
+func (con lt) DeviceMaintenance(name string, opt MaintenanceOptions) (err error) {
+	err = con.c.DevicesUpdateContext(con.ctx, name, lava.DeviceSettings{Health: "MAINTENANCE"})
+	if err != nil {
+		return
+	}
+	if !opt.Wait && !opt.Force {
+		return
+	}
+	// Don't poll the server in a busy loop
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = DefaultOptions.PollInterval
+	}
+
+	var dev *lava.Device
+	start := time.Now()
+	canceled := false
+	for {
+		dev, err = con.retry.GetDevice(con.ctx, name)
+		if err != nil {
+			return
+		}
+		if dev.CurrentJob == 0 {
+			return
+		}
+		if opt.Force && !canceled {
+			err = con.CancelJobWithRetry(dev.CurrentJob)
+			if err != nil {
+				return
+			}
+			canceled = true
+		}
+		if opt.Timeout > 0 && time.Since(start) > opt.Timeout {
+			err = fmt.Errorf("Timeout waiting for job %d on device %s", dev.CurrentJob, name)
+			return
+		}
+		err = sleep(con.ctx, opt.PollInterval)
+		if err != nil {
+			return
+		}
+	}
+}
+
+//DeviceRestore sets the device health to UNKNOWN, which triggers a health check
+func (con lt) DeviceRestore(name string) (err error) {
+	err = con.c.DevicesUpdateContext(con.ctx, name, lava.DeviceSettings{Health: "UNKNOWN"})
+
+	return
+}
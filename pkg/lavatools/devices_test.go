@@ -2,126 +2,66 @@ package lavatools
 
 import (
 	"testing"
-	"time"
 
 	"github.com/siro20/lavacli/pkg/lava"
 )
 
 func Test_lt_DeviceListHealthyCached(t *testing.T) {
-
-	lavacon, err := lava.ConnectByConfigID("", lava.DefaultOptions)
-	if err != nil {
-		t.Skip()
-	}
-	con, err := NewLavaTools(lavacon, DefaultOptions)
-	if err != nil {
-		t.Skip()
-	}
-
-	startUncached := time.Now()
-	_, err = lavacon.DevicesList()
-	if err != nil {
-		t.Errorf("DeviceListHealthyCached() got unexpected error = %v", err)
-		return
+	f, con := newFakeTools(t)
+	if err := f.DevicesAdd("qemu02", lava.DeviceSettings{DeviceType: "qemu", Worker: "worker01", Health: "Maintenance"}); err != nil {
+		t.Fatal(err)
 	}
 
-	elapsedUncached := time.Since(startUncached)
-
-	_, err = con.DeviceListHealthyCached()
-	if err != nil {
-		t.Errorf("DeviceListHealthyCached() got unexpected error = %v", err)
-		return
-	}
-
-	startCached := time.Now()
-	_, err = con.DeviceListHealthyCached()
-	if err != nil {
-		t.Errorf("DeviceListHealthyCached() got unexpected error = %v", err)
-		return
+	for i := 0; i < 2; i++ {
+		list, err := con.DeviceListHealthyCached()
+		if err != nil {
+			t.Fatalf("DeviceListHealthyCached() got unexpected error = %v", err)
+		}
+		if len(list) != 1 || list[0].Hostname != "qemu01" {
+			t.Errorf("DeviceListHealthyCached() = %+v, want qemu01", list)
+		}
 	}
-	elapsedCached := time.Since(startCached)
-	if elapsedCached*100 > elapsedUncached {
-		t.Errorf("DeviceListHealthyCached took very long to return: cached = %v, uncached = %v", elapsedCached, elapsedUncached)
-		return
+	if n := f.Calls("DevicesList"); n != 1 {
+		t.Errorf("DevicesList called %d times, want 1", n)
 	}
 }
 
 func Test_lt_DeviceListCached(t *testing.T) {
-
-	lavacon, err := lava.ConnectByConfigID("", lava.DefaultOptions)
-	if err != nil {
-		t.Skip()
-	}
-	con, err := NewLavaTools(lavacon, DefaultOptions)
-	if err != nil {
-		t.Skip()
+	f, con := newFakeTools(t)
+	if err := f.DevicesAdd("qemu02", lava.DeviceSettings{DeviceType: "qemu", Worker: "worker01", Health: "Maintenance"}); err != nil {
+		t.Fatal(err)
 	}
 
-	startUncached := time.Now()
-	_, err = lavacon.DevicesList()
-	if err != nil {
-		t.Errorf("DeviceListCached() got unexpected error = %v", err)
-		return
-	}
-
-	elapsedUncached := time.Since(startUncached)
-
-	_, err = con.DeviceListCached()
-	if err != nil {
-		t.Errorf("DeviceListCached() got unexpected error = %v", err)
-		return
-	}
-
-	startCached := time.Now()
-	_, err = con.DeviceListCached()
-	if err != nil {
-		t.Errorf("DeviceListCached() got unexpected error = %v", err)
-		return
+	for i := 0; i < 2; i++ {
+		list, err := con.DeviceListCached()
+		if err != nil {
+			t.Fatalf("DeviceListCached() got unexpected error = %v", err)
+		}
+		if len(list) != 2 {
+			t.Errorf("DeviceListCached() = %+v, want qemu01 and qemu02", list)
+		}
 	}
-	elapsedCached := time.Since(startCached)
-	if elapsedCached*100 > elapsedUncached {
-		t.Errorf("DeviceListCached took very long to return: cached = %v, uncached = %v", elapsedCached, elapsedUncached)
-		return
+	if n := f.Calls("DevicesList"); n != 1 {
+		t.Errorf("DevicesList called %d times, want 1", n)
 	}
 }
 
 func Test_lt_DevicesTypesTemplateGetCached(t *testing.T) {
-
-	lavacon, err := lava.ConnectByConfigID("", lava.DefaultOptions)
-	if err != nil {
-		t.Skip()
-	}
-	con, err := NewLavaTools(lavacon, DefaultOptions)
-	if err != nil {
-		t.Skip()
+	f, con := newFakeTools(t)
+	if err := f.DevicesTypesTemplateSet("qemu", "{% extends 'qemu.jinja2' %}"); err != nil {
+		t.Fatal(err)
 	}
 
-	startUncached := time.Now()
-	devTypes, err := lavacon.DevicesTypesList(false)
-	if err != nil {
-		t.Errorf("DeviceListCached() got unexpected error = %v", err)
-		return
-	}
-
-	elapsedUncached := time.Since(startUncached)
-
-	for _, dtype := range devTypes {
-		_, err = con.DevicesTypesTemplateGetCached(dtype.Name)
+	for i := 0; i < 2; i++ {
+		template, err := con.DevicesTypesTemplateGetCached("qemu")
 		if err != nil {
-			t.Errorf("DevicesTypesTemplateGetCached(%s) got unexpected error = %v", dtype.Name, err)
-			return
+			t.Fatalf("DevicesTypesTemplateGetCached(qemu) got unexpected error = %v", err)
 		}
-
-		startCached := time.Now()
-		_, err = con.DevicesTypesTemplateGetCached(dtype.Name)
-		if err != nil {
-			t.Errorf("DevicesTypesTemplateGetCached(%s) got unexpected error = %v", dtype.Name, err)
-			return
-		}
-		elapsedCached := time.Since(startCached)
-		if elapsedCached*100 > elapsedUncached {
-			t.Errorf("DevicesTypesTemplateGetCached(%s) took very long to return: cached = %v, uncached = %v", dtype.Name, elapsedCached, elapsedUncached)
-			return
+		if template != "{% extends 'qemu.jinja2' %}" {
+			t.Errorf("DevicesTypesTemplateGetCached(qemu) = %q", template)
 		}
 	}
+	if n := f.Calls("DevicesTypesTemplateGet"); n != 1 {
+		t.Errorf("DevicesTypesTemplateGet called %d times, want 1", n)
+	}
 }
@@ -4,7 +4,7 @@ package lavatools
 // Retries to get the template in case of error
 func (con lt) DevicesTypesTemplateGetWithRetry(name string) (ret string, err error) {
 
-	ret, err = con.retry.GetDeviceTypeTemplates(name)
+	ret, err = con.retry.GetDeviceTypeTemplates(con.ctx, name)
 
 	return
 }
@@ -12,6 +12,6 @@ func (con lt) DevicesTypesTemplateGetWithRetry(name string) (ret string, err err
 // DevicesTypesTemplateGetCached returns the cached device type template
 func (con lt) DevicesTypesTemplateGetCached(name string) (ret string, err error) {
 
-	ret, err = con.cache.GetDeviceTypeTemplates(name)
+	ret, err = con.cache.GetDeviceTypeTemplates(con.ctx, name)
 	return
 }
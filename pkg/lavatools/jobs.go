@@ -1,8 +1,10 @@
 package lavatools
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/siro20/lavacli/pkg/lava"
@@ -194,7 +196,7 @@ func (con lt) LaunchJob(job lava.JobStruct, opt JobOptions) (int, error) {
 //JobsValidate validates the job definition
 func (con lt) JobsValidate(jobYaml string) (msg string, err error) {
 
-	jobErrors, err := con.c.JobsValidate(jobYaml, false)
+	jobErrors, err := con.c.JobsValidateContext(con.ctx, jobYaml, false)
 	if err != nil {
 		return
 	}
@@ -211,9 +213,9 @@ func (con lt) JobsValidate(jobYaml string) (msg string, err error) {
 func (con lt) JobsSubmitWithRetry(job *lava.JobStruct) (id int, err error) {
 	var ids []int
 	id = -1
-	ids, err = con.c.JobsSubmit(job)
+	ids, err = con.c.JobsSubmitContext(con.ctx, job)
 	if err != nil {
-		err = fmt.Errorf("JobsSubmit returned error: %v\n", err)
+		err = fmt.Errorf("JobsSubmit returned error: %w\n", err)
 		return
 	}
 	if len(ids) == 0 {
@@ -229,9 +231,9 @@ func (con lt) JobsSubmitWithRetry(job *lava.JobStruct) (id int, err error) {
 func (con lt) JobsSubmitStringWithRetry(jobYaml string) (id int, err error) {
 	var ids []int
 	id = -1
-	ids, err = con.c.JobsSubmitString(jobYaml)
+	ids, err = con.c.JobsSubmitStringContext(con.ctx, jobYaml)
 	if err != nil {
-		err = fmt.Errorf("JobsSubmit returned error: %v\n", err)
+		err = fmt.Errorf("JobsSubmit returned error: %w\n", err)
 		return
 	}
 	if len(ids) == 0 {
@@ -246,9 +248,15 @@ func (con lt) JobsSubmitStringWithRetry(jobYaml string) (id int, err error) {
 func (con lt) JobsShowWithRetry(id int) (state *lava.JobState, err error) {
 
 	for i := 0; i < 5; i++ {
-		state, err = con.c.JobsShow(id)
+		state, err = con.c.JobsShowContext(con.ctx, id)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -262,9 +270,15 @@ func (con lt) JobsShowWithRetry(id int) (state *lava.JobState, err error) {
 func (con lt) JobsDefinitionWithRetry(id int) (job *lava.JobStruct, err error) {
 	var def lava.JobDefintion
 	for i := 0; i < 5; i++ {
-		def, err = con.c.JobsDefinition(id)
+		def, err = con.c.JobsDefinitionContext(con.ctx, id)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -285,10 +299,16 @@ func (con lt) JobsDefinitionWithRetry(id int) (job *lava.JobStruct, err error) {
 //CancelJobWithRetry cancels the job of a given job ID
 func (con lt) CancelJobWithRetry(id int) (err error) {
 	for i := 0; i < 5; i++ {
-		err = con.c.JobsCancel(id)
+		err = con.c.JobsCancelContext(con.ctx, id)
 
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -300,9 +320,15 @@ func (con lt) CancelJobWithRetry(id int) (err error) {
 // Retries to get the list in case of error
 func (con lt) QueryJobListWithRetry(state string, health string, start int, limit int) (list []lava.JobsListing, err error) {
 	for i := 0; i < 5; i++ {
-		list, err = con.c.JobsList(state, health, start, limit)
+		list, err = con.c.JobsListContext(con.ctx, state, health, start, limit)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -310,3 +336,55 @@ func (con lt) QueryJobListWithRetry(state string, health string, start int, limi
 
 	return
 }
+
+//QueryJobListFilteredWithRetry returns up to limit jobs matching the filter,
+//all of them if limit is zero. Retries to get the list in case of error
+func (con lt) QueryJobListFilteredWithRetry(filter lava.JobsFilter, start int, limit int) (list []lava.JobsListing, err error) {
+	for i := 0; i < 5; i++ {
+		list, err = con.c.JobsListFilteredContext(con.ctx, filter, start, limit)
+		if err != nil {
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
+			continue
+		}
+		break
+	}
+
+	return
+}
+
+//ErrWaitTimeout is returned by WaitForJob if the job didn't finish in time
+var ErrWaitTimeout = errors.New("Timeout waiting for job to finish")
+
+//WaitForJob polls the job state every interval until the job reached the
+//FINISHED state and returns the final state. A timeout of zero waits forever.
+//An interval of zero uses DefaultOptions.PollInterval.
+func (con lt) WaitForJob(id int, interval time.Duration, timeout time.Duration) (state *lava.JobState, err error) {
+	// Don't poll the server in a busy loop
+	if interval <= 0 {
+		interval = DefaultOptions.PollInterval
+	}
+	start := time.Now()
+	for {
+		state, err = con.JobsShowWithRetry(id)
+		if err != nil {
+			return
+		}
+		if strings.ToLower(state.State) == "finished" {
+			return
+		}
+		if timeout > 0 && time.Since(start) > timeout {
+			err = ErrWaitTimeout
+			return
+		}
+		err = sleep(con.ctx, interval)
+		if err != nil {
+			return
+		}
+	}
+}
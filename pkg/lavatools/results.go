@@ -14,9 +14,15 @@ import (
 func (con lt) GetJobTestResultsWithRetry(id int) (ret lava.Result, err error) {
 	var r lava.Result
 	for i := 0; i < 5; i++ {
-		r, err = con.c.Results(id)
+		r, err = con.c.ResultsContext(con.ctx, id)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(con.ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -1,6 +1,9 @@
 package lavatools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/siro20/lavacli/pkg/lava"
 
 	"time"
@@ -8,15 +11,21 @@ import (
 
 //rety implements a retry layer for lavatools
 type retry struct {
-	c          *lava.Connection
+	c          lava.Client
 	retryCount int
 }
 
-func (r *retry) GetDeviceList() (devList []lava.DeviceList, err error) {
+func (r *retry) GetDeviceList(ctx context.Context) (devList []lava.DeviceList, err error) {
 	for i := 0; i <= r.retryCount; i++ {
-		devList, err = r.c.DevicesList()
+		devList, err = r.c.DevicesListContext(ctx)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -24,11 +33,17 @@ func (r *retry) GetDeviceList() (devList []lava.DeviceList, err error) {
 	return
 }
 
-func (r *retry) GetDevice(name string) (dev *lava.Device, err error) {
+func (r *retry) GetDevice(ctx context.Context, name string) (dev *lava.Device, err error) {
 	for i := 0; i <= r.retryCount; i++ {
-		dev, err = r.c.DevicesShow(name)
+		dev, err = r.c.DevicesShowContext(ctx, name)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -37,11 +52,17 @@ func (r *retry) GetDevice(name string) (dev *lava.Device, err error) {
 	return
 }
 
-func (r *retry) GetDeviceTypeTemplates(name string) (template string, err error) {
+func (r *retry) GetDeviceTypeTemplates(ctx context.Context, name string) (template string, err error) {
 	for i := 0; i <= r.retryCount; i++ {
-		template, err = r.c.DevicesTypesTemplateGet(name)
+		template, err = r.c.DevicesTypesTemplateGetContext(ctx, name)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 
@@ -51,11 +72,17 @@ func (r *retry) GetDeviceTypeTemplates(name string) (template string, err error)
 	return
 }
 
-func (r *retry) GetDeviceTagsList(name string) (tags []string, err error) {
+func (r *retry) GetDeviceTagsList(ctx context.Context, name string) (tags []string, err error) {
 	for i := 0; i <= r.retryCount; i++ {
-		tags, err = r.c.DevicesTagsList(name)
+		tags, err = r.c.DevicesTagsListContext(ctx, name)
 		if err != nil {
-			time.Sleep(time.Second * 15)
+			if !retryable(err) {
+				return
+			}
+			if serr := sleep(ctx, time.Second*15); serr != nil {
+				err = serr
+				return
+			}
 			continue
 		}
 		break
@@ -64,8 +91,28 @@ func (r *retry) GetDeviceTagsList(name string) (tags []string, err error) {
 	return
 }
 
+//retryable returns false for errors that won't go away by retrying
+func retryable(err error) bool {
+	return !errors.Is(err, lava.ErrNotFound) &&
+		!errors.Is(err, lava.ErrPermissionDenied) &&
+		!errors.Is(err, lava.ErrInvalidRequest)
+}
+
+//sleep pauses for d. Returns the context's error if ctx is done earlier.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 //newLavaToolsRetry returns a retry object
-func newLavaToolsRetry(c *lava.Connection, opt Options) (obj *retry, err error) {
+func newLavaToolsRetry(c lava.Client, opt Options) (obj *retry, err error) {
 	obj = &retry{c: c,
 		retryCount: opt.RetryCount,
 	}
@@ -1,6 +1,8 @@
 package lavatools
 
 import (
+	"context"
+
 	"github.com/siro20/lavacli/pkg/lava"
 
 	"time"
@@ -59,6 +61,27 @@ var DefaultJobOptions = JobOptions{
 	Visibility: defaultVisibility,
 }
 
+// MaintenanceOptions control how a device is taken out of service
+type MaintenanceOptions struct {
+	// Wait blocks until the current job of the device has finished
+	Wait bool
+	// Force cancels the current job of the device and waits until it's gone
+	Force bool
+	// PollInterval sets the interval between two device state checks.
+	// Zero uses DefaultOptions.PollInterval.
+	PollInterval time.Duration
+	// Timeout is the maximum duration to wait for the current job. Zero waits forever.
+	Timeout time.Duration
+}
+
+// DefaultMaintenanceOptions are to be used as default
+var DefaultMaintenanceOptions = MaintenanceOptions{
+	Wait:         true,
+	Force:        false,
+	PollInterval: time.Second * 15,
+	Timeout:      0,
+}
+
 type Options struct {
 	//RetryCount is the number of retries done in the *Retry methods
 	//before the error is returned to the caller
@@ -88,7 +111,8 @@ var DefaultOptions = Options{
 
 //lt implements the Lavatools interface
 type lt struct {
-	c              *lava.Connection
+	ctx            context.Context
+	c              lava.Client
 	pollInterval   time.Duration
 	invalidTimeout time.Duration
 	retryCount     int
@@ -97,6 +121,8 @@ type lt struct {
 }
 
 type Lavatools interface {
+	// WithContext returns a copy using ctx for all requests
+	WithContext(ctx context.Context) Lavatools
 	// jobs
 	LaunchJob(job lava.JobStruct, opt JobOptions) (int, error)
 	JobsValidate(jobYaml string) (msg string, err error)
@@ -105,11 +131,20 @@ type Lavatools interface {
 	JobsShowWithRetry(id int) (state *lava.JobState, err error)
 	JobsDefinitionWithRetry(id int) (job *lava.JobStruct, err error)
 	QueryJobListWithRetry(state string, health string, start int, limit int) (list []lava.JobsListing, err error)
+	QueryJobListFilteredWithRetry(filter lava.JobsFilter, start int, limit int) (list []lava.JobsListing, err error)
 	CancelJobWithRetry(id int) (err error)
+	WaitForJob(id int, interval time.Duration, timeout time.Duration) (state *lava.JobState, err error)
+	// bulk jobs
+	SelectJobs(sel JobSelector, parallel int) (list []lava.JobsListing, failed []JobOperationResult, err error)
+	CancelJobs(ids []int, parallel int) []JobOperationResult
+	FailJobs(ids []int, parallel int) []JobOperationResult
+	ResubmitJobs(ids []int, parallel int) []JobOperationResult
 	// results
 	GetJobTestResultsWithRetry(id int) (ret lava.Result, err error)
 	// device
 	DeviceListWithRetry() (defList []lava.DeviceList, err error)
+	DeviceMaintenance(name string, opt MaintenanceOptions) (err error)
+	DeviceRestore(name string) (err error)
 	// device tags
 	DevicesTagsListWithRetry(name string) (ret []string, err error)
 	// device-types template
@@ -124,7 +159,7 @@ type Lavatools interface {
 }
 
 //NewLavaTools returns an interface to Lavatools
-func NewLavaTools(c *lava.Connection, opt Options) (con Lavatools, err error) {
+func NewLavaTools(c lava.Client, opt Options) (con Lavatools, err error) {
 	retry, err := newLavaToolsRetry(c, opt)
 	if err != nil {
 		return
@@ -135,7 +170,8 @@ func NewLavaTools(c *lava.Connection, opt Options) (con Lavatools, err error) {
 		return
 	}
 
-	obj := lt{c: c,
+	obj := lt{ctx: context.Background(),
+		c:              c,
 		pollInterval:   opt.PollInterval,
 		invalidTimeout: opt.InvalidTimeout,
 		retryCount:     opt.RetryCount,
@@ -145,3 +181,11 @@ func NewLavaTools(c *lava.Connection, opt Options) (con Lavatools, err error) {
 	con = obj
 	return
 }
+
+//WithContext returns a copy of the Lavatools using ctx for all requests.
+//Retry and poll loops stop sleeping and return the context's error once
+//ctx is done.
+func (con lt) WithContext(ctx context.Context) Lavatools {
+	con.ctx = ctx
+	return con
+}
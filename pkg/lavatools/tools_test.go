@@ -0,0 +1,131 @@
+package lavatools
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/siro20/lavacli/pkg/lava"
+	"github.com/siro20/lavacli/pkg/lava/lavafake"
+)
+
+var fakeOptions = Options{
+	RetryCount:            1,
+	PollInterval:          time.Minute,
+	InvalidTimeout:        time.Hour,
+	BackgroundPrefetching: false,
+}
+
+// newFakeTools returns lavatools using a fake server with the device qemu01 running job 1
+func newFakeTools(t *testing.T) (*lavafake.Fake, Lavatools) {
+	f := lavafake.New()
+	f.DevicesTypesAdd("qemu", lava.DeviceTypeSettings{})
+	f.WorkersAdd("worker01", "", false)
+	f.DevicesAdd("qemu01", lava.DeviceSettings{DeviceType: "qemu", Worker: "worker01", Health: "Good"})
+	f.DevicesTagsAdd("qemu01", "usb")
+	ids, err := f.JobsSubmitString("device_type: qemu\njob_name: test\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.StartJob(ids[0], "qemu01"); err != nil {
+		t.Fatal(err)
+	}
+
+	con, err := NewLavaTools(f, fakeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, con
+}
+
+func Test_lt_DeviceMaintenance_force(t *testing.T) {
+	f, con := newFakeTools(t)
+
+	err := con.DeviceMaintenance("qemu01", MaintenanceOptions{Force: true, PollInterval: time.Millisecond, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, _ := f.DevicesShow("qemu01")
+	if dev.Health != "Maintenance" || dev.CurrentJob != 0 {
+		t.Errorf("device = %+v, want idle device in maintenance", dev)
+	}
+	job, _ := f.JobsShow(1)
+	if job.State != "Finished" || job.Health != "Canceled" {
+		t.Errorf("job = %+v, want canceled job", job)
+	}
+
+	err = con.DeviceRestore("qemu01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, _ = f.DevicesShow("qemu01")
+	if dev.Health != "Unknown" {
+		t.Errorf("device health = %s, want Unknown", dev.Health)
+	}
+}
+
+func Test_lt_DeviceMaintenance_timeout(t *testing.T) {
+	_, con := newFakeTools(t)
+
+	err := con.DeviceMaintenance("qemu01", MaintenanceOptions{Wait: true, PollInterval: time.Millisecond, Timeout: 10 * time.Millisecond})
+	if err == nil {
+		t.Errorf("DeviceMaintenance() expected timeout waiting for job 1")
+	}
+}
+
+func Test_lt_noRetryOnNotFound(t *testing.T) {
+	f, con := newFakeTools(t)
+
+	_, err := con.JobsShowWithRetry(42)
+	if !errors.Is(err, lava.ErrNotFound) {
+		t.Errorf("JobsShowWithRetry() error = %v, want ErrNotFound", err)
+	}
+	if n := f.Calls("JobsShow"); n != 1 {
+		t.Errorf("JobsShow called %d times, want 1", n)
+	}
+
+	f.SetError("DevicesTagsList", &lava.PermissionDeniedError{Fault: lava.Fault{Code: 403, Message: "Permission denied"}})
+	_, err = con.DevicesTagsListWithRetry("qemu01")
+	if !errors.Is(err, lava.ErrPermissionDenied) {
+		t.Errorf("DevicesTagsListWithRetry() error = %v, want ErrPermissionDenied", err)
+	}
+	if n := f.Calls("DevicesTagsList"); n != 1 {
+		t.Errorf("DevicesTagsList called %d times, want 1", n)
+	}
+}
+
+func Test_lt_DeviceListCached_fake(t *testing.T) {
+	f, con := newFakeTools(t)
+
+	for i := 0; i < 3; i++ {
+		alive, err := con.DeviceOfTypeIsAliveAndHasTagCached("qemu", []string{"usb"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !alive {
+			t.Errorf("DeviceOfTypeIsAliveAndHasTagCached() = false, want true")
+		}
+	}
+	if n := f.Calls("DevicesList"); n != 1 {
+		t.Errorf("DevicesList called %d times, want 1", n)
+	}
+	if n := f.Calls("DevicesTagsList"); n != 1 {
+		t.Errorf("DevicesTagsList called %d times, want 1", n)
+	}
+}
+
+func Test_lt_WaitForJob(t *testing.T) {
+	f, con := newFakeTools(t)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.SetJobState(1, "Finished", "Complete")
+	}()
+	state, err := con.WaitForJob(1, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Health != "Complete" {
+		t.Errorf("WaitForJob() health = %s, want Complete", state.Health)
+	}
+}